@@ -1,20 +1,17 @@
 package confs
 
-import (
-	"encoding/json"
-	"errors"
-	"io/ioutil"
-	"os"
-
-	"github.com/ipfs/go-ipfs-api"
-
-	uuid "github.com/satori/go.uuid"
-)
-
 type configuration struct {
 	IpfsConnection string
 	AbciDaemon     string
 	Inflators      []string
+	KeepVersions   int64
+	DBDir          string  // the directory of the database, the state is kept in the memory when it is empty
+	Fetcher        Fetcher // the fetcher of the content by hash, the daemon of IPFS when it is nil
+
+	// the hash of the first version of the inflators that is trusted, it is needed for the later versions
+	InflatorsRoot string
+	// the versions of the inflators from the hash, the Inflators are used without them
+	InflatorsDocuments []InflatorsDocument
 }
 
 var Conf = configuration{}
@@ -25,23 +22,35 @@ func init() {
 	Conf.Inflators = []string{}
 }
 
+func (c *configuration) fetcher() Fetcher {
+	if c.Fetcher != nil {
+		return c.Fetcher
+	}
+	return NewIpfsFetcher(c.IpfsConnection)
+}
+
+// SetInflatorsFromHash sets the inflators from the json of the hash, after the json has been verified against the hash.
+// The versions of the inflators are verified back to the first version, and the Inflators are the latest version.
 func (c *configuration) SetInflatorsFromHash(hash string) error {
-	sh := shell.NewShell(c.IpfsConnection)
-	file := uuid.NewV4().String()
-	err := sh.Get(hash, file)
+	docs, err := fetchInflatorsDocuments(c.fetcher(), hash, c.InflatorsRoot)
 	if err != nil {
-		return errors.New("Failed to get the json file for the inflators " + hash + ": " + err.Error())
+		return err
 	}
-	b, err := ioutil.ReadFile(file)
-	if err != nil {
-		return errors.New("Failed to read the json file from the hash " + hash + " for the inflators: " + err.Error())
+	c.InflatorsDocuments = docs
+	c.Inflators = docs[len(docs)-1].Inflators
+	return nil
+}
+
+// GetInflators returns the inflators of the latest version that has been activated on the height,
+// the first version is used before the activation of the others
+func (c *configuration) GetInflators(height int64) []string {
+	if len(c.InflatorsDocuments) == 0 {
+		return c.Inflators
 	}
-	os.RemoveAll(file)
-	inflators := []string{}
-	err = json.Unmarshal(b, &inflators)
-	if err != nil {
-		return errors.New("The json file for the inflators has not the correct JSON format: " + err.Error())
+	for i := len(c.InflatorsDocuments) - 1; i > 0; i-- {
+		if c.InflatorsDocuments[i].ActivationHeight <= height {
+			return c.InflatorsDocuments[i].Inflators
+		}
 	}
-	c.Inflators = inflators
-	return nil
+	return c.InflatorsDocuments[0].Inflators
 }
@@ -0,0 +1,130 @@
+package confs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/ipfs/go-ipfs-api"
+	mh "github.com/multiformats/go-multihash"
+)
+
+// IPFS_CHUNK_SIZE is the size of the chunks that `ipfs add` splits the files in,
+// the files in one chunk can be verified against their IPFS hash
+const IPFS_CHUNK_SIZE = 262144
+
+var (
+	ERR_HASH_NOT_CORRECT = func(hash string) error {
+		return errors.New("The hash " + hash + " is not a correct multihash.")
+	}
+	ERR_CONTENT_NOT_EQUAL_TO_HASH = func(hash string) error {
+		return errors.New("The content is not equal to the hash " + hash + ".")
+	}
+)
+
+// Fetcher gets the content of a hash, and it verifies the content against the hash
+type Fetcher interface {
+	Fetch(hash string) ([]byte, error)
+}
+
+// IpfsFetcher gets the content from the daemon of IPFS
+type IpfsFetcher struct {
+	Connection string
+}
+
+func NewIpfsFetcher(connection string) *IpfsFetcher {
+	return &IpfsFetcher{Connection: connection}
+}
+
+func (f *IpfsFetcher) Fetch(hash string) ([]byte, error) {
+	m, err := mh.FromB58String(hash)
+	if err != nil {
+		return nil, ERR_HASH_NOT_CORRECT(hash)
+	}
+	sh := shell.NewShell(f.Connection)
+	r, err := sh.Cat(hash)
+	if err != nil {
+		return nil, errors.New("Failed to get the content of the hash " + hash + " from IPFS: " + err.Error())
+	}
+	defer r.Close()
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.New("Failed to read the content of the hash " + hash + " from IPFS: " + err.Error())
+	}
+	return b, verifyContent(hash, m, b)
+}
+
+// DirFetcher gets the content from the files of a directory, that are named by the hash of their content,
+// so the validators and the tests can run without IPFS
+type DirFetcher struct {
+	Dir string
+}
+
+func NewDirFetcher(dir string) *DirFetcher {
+	return &DirFetcher{Dir: dir}
+}
+
+func (f *DirFetcher) Fetch(hash string) ([]byte, error) {
+	// the hash is checked first, so it can not be a path out of the directory
+	m, err := mh.FromB58String(hash)
+	if err != nil {
+		return nil, ERR_HASH_NOT_CORRECT(hash)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(f.Dir, hash))
+	if err != nil {
+		return nil, errors.New("Failed to read the content of the hash " + hash + ": " + err.Error())
+	}
+	return b, verifyContent(hash, m, b)
+}
+
+// ContentHash returns the sha2-256 multihash of the content, the name of its file for the DirFetcher
+func ContentHash(b []byte) string {
+	m, _ := mh.Sum(b, mh.SHA2_256, -1)
+	return m.B58String()
+}
+
+// verifyContent verifies that the hash is the multihash of the content,
+// or the IPFS hash of the file with the content, when the file is in one chunk
+func verifyContent(hash string, m mh.Multihash, b []byte) error {
+	dm, err := mh.Decode(m)
+	if err != nil {
+		return ERR_HASH_NOT_CORRECT(hash)
+	}
+	sum, err := mh.Sum(b, dm.Code, dm.Length)
+	if err == nil && bytes.Equal(sum, m) {
+		return nil
+	}
+	if len(b) <= IPFS_CHUNK_SIZE {
+		sum, err = mh.Sum(ipfsFileNode(b), dm.Code, dm.Length)
+		if err == nil && bytes.Equal(sum, m) {
+			return nil
+		}
+	}
+	return ERR_CONTENT_NOT_EQUAL_TO_HASH(hash)
+}
+
+// ipfsFileNode returns the protobuf of the dag-pb node that `ipfs add` creates for a file in one chunk,
+// the unixfs data of the file without links
+func ipfsFileNode(b []byte) []byte {
+	data := []byte{0x08, 0x02} // the type of the file
+	if len(b) > 0 {
+		data = appendBytesField(data, 0x12, b)
+	}
+	data = append(data, 0x18)
+	data = appendUvarint(data, uint64(len(b)))
+	return appendBytesField([]byte{}, 0x0a, data)
+}
+
+func appendBytesField(buf []byte, tag byte, b []byte) []byte {
+	buf = append(buf, tag)
+	buf = appendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	vb := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(vb, v)
+	return append(buf, vb[:n]...)
+}
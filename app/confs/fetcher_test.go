@@ -0,0 +1,68 @@
+package confs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the IPFS hash of the file with "hello world\n"
+const helloWorldIpfsHash = "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o"
+
+func newContentDir(t *testing.T, files map[string][]byte) string {
+	dir, err := ioutil.TempDir("", "content")
+	assert.Nil(t, err)
+	for name, b := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), b, 0644))
+	}
+	return dir
+}
+
+func TestDirFetcherVerifiesTheContentHash(t *testing.T) {
+	b := []byte(`["abcd"]`)
+	hash := ContentHash(b)
+	dir := newContentDir(t, map[string][]byte{hash: b})
+	defer os.RemoveAll(dir)
+
+	fb, err := NewDirFetcher(dir).Fetch(hash)
+	assert.Nil(t, err)
+	assert.Equal(t, b, fb)
+}
+
+func TestDirFetcherVerifiesTheIpfsHash(t *testing.T) {
+	b := []byte("hello world\n")
+	dir := newContentDir(t, map[string][]byte{helloWorldIpfsHash: b})
+	defer os.RemoveAll(dir)
+
+	fb, err := NewDirFetcher(dir).Fetch(helloWorldIpfsHash)
+	assert.Nil(t, err)
+	assert.Equal(t, b, fb)
+}
+
+func TestDirFetcherFailOnContentNotEqualToHash(t *testing.T) {
+	dir := newContentDir(t, map[string][]byte{helloWorldIpfsHash: []byte("hello world")})
+	defer os.RemoveAll(dir)
+
+	_, err := NewDirFetcher(dir).Fetch(helloWorldIpfsHash)
+	assert.Equal(t, ERR_CONTENT_NOT_EQUAL_TO_HASH(helloWorldIpfsHash), err)
+}
+
+func TestDirFetcherFailOnHashNotCorrect(t *testing.T) {
+	_, err := NewDirFetcher(os.TempDir()).Fetch("../inflators.json")
+	assert.Equal(t, ERR_HASH_NOT_CORRECT("../inflators.json"), err)
+}
+
+func TestSetInflatorsFromHashWithTheFetcher(t *testing.T) {
+	b := []byte(`["abcd","ef01"]`)
+	hash := ContentHash(b)
+	dir := newContentDir(t, map[string][]byte{hash: b})
+	defer os.RemoveAll(dir)
+
+	c := configuration{Fetcher: NewDirFetcher(dir)}
+	err := c.SetInflatorsFromHash(hash)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"abcd", "ef01"}, c.Inflators)
+}
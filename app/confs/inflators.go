@@ -0,0 +1,178 @@
+package confs
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dedis/kyber"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+)
+
+var (
+	ERR_INFLATORS_EMPTY                 = errors.New("The list of the inflators is empty.")
+	ERR_INFLATORS_VERSION_NOT_CORRECT   = errors.New("The version of the inflators can not be under 1.")
+	ERR_INFLATORS_VERSION_NOT_NEXT      = errors.New("The version of the inflators is not the next of the previous version.")
+	ERR_INFLATORS_ACTIVATION_NOT_AFTER  = errors.New("The activation height of the inflators is not after the activation height of the previous version.")
+	ERR_INFLATORS_PREVIOUS_MISSING      = errors.New("The inflators after the first version do not have the hash of the previous version.")
+	ERR_INFLATORS_FIRST_HAS_PREVIOUS    = errors.New("The first version of the inflators can not have a previous version.")
+	ERR_INFLATORS_SIGNATURES_NOT_ENOUGH = func(valid, needed int) error {
+		return errors.New(fmt.Sprint("The inflators have ", valid, " valid signatures of the previous inflators, they need ", needed, "."))
+	}
+	ERR_INFLATORS_ROOT_NOT_EQUAL = func(hash string) error {
+		return errors.New("The first version of the inflators is not the root " + hash + ".")
+	}
+	ERR_INFLATORS_ROOT_MISSING = errors.New("The inflators after the first version need the root of the first version that is trusted.")
+	ERR_INFLATORS_DUPLICATE    = func(inflator string) error {
+		return errors.New("The inflator " + inflator + " is in the list more than once.")
+	}
+)
+
+// InflatorsDocument is the list of the inflators that is fetched by hash.
+// Every version after the first is signed by the inflators of the previous version, so the list
+// can be rotated with a new hash only by the inflators that the network trusts already.
+type InflatorsDocument struct {
+	Version          int64    // the first version is 1, and every rotation increases it by one
+	ActivationHeight int64    // the height that the inflators are used from
+	Inflators        []string // public keys hex
+	Previous         string   `json:",omitempty"` // the hash of the previous version, empty for the first version
+
+	// the signatures hex of the previous inflators on the SignBytes, by their public key
+	Signatures map[string]string `json:",omitempty"`
+}
+
+// SignBytes are the bytes that the previous inflators sign, the document without the signatures
+func (d *InflatorsDocument) SignBytes() []byte {
+	unsigned := *d
+	unsigned.Signatures = nil
+	b, _ := json.Marshal(unsigned)
+	return b
+}
+
+// Sign adds the signature of the inflator to the document
+func (d *InflatorsDocument) Sign(inflator string, priv kyber.Scalar) error {
+	sig, err := utils.Sign(priv, d.SignBytes())
+	if err != nil {
+		return err
+	}
+	if d.Signatures == nil {
+		d.Signatures = map[string]string{}
+	}
+	d.Signatures[inflator] = sig
+	return nil
+}
+
+// Verify verifies the document as the next version of the previous document,
+// signed by more than half of the previous inflators
+func (d *InflatorsDocument) Verify(prev *InflatorsDocument) error {
+	if len(d.Inflators) == 0 {
+		return ERR_INFLATORS_EMPTY
+	}
+	// an inflator that is twice in the previous list would have its signature counted twice
+	err := validateInflatorsUnique(prev.Inflators)
+	if err != nil {
+		return err
+	}
+	err = validateInflatorsUnique(d.Inflators)
+	if err != nil {
+		return err
+	}
+	if d.Version != prev.Version+1 {
+		return ERR_INFLATORS_VERSION_NOT_NEXT
+	}
+	if d.ActivationHeight <= prev.ActivationHeight {
+		return ERR_INFLATORS_ACTIVATION_NOT_AFTER
+	}
+	msg := d.SignBytes()
+	valid := 0
+	for _, inflator := range prev.Inflators {
+		sig, err := hex.DecodeString(d.Signatures[inflator])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+		isValid, err := utils.Verify(inflator, sig, msg)
+		if err == nil && isValid {
+			valid++
+		}
+	}
+	needed := len(prev.Inflators)/2 + 1
+	if valid < needed {
+		return ERR_INFLATORS_SIGNATURES_NOT_ENOUGH(valid, needed)
+	}
+	return nil
+}
+
+func validateInflatorsUnique(inflators []string) error {
+	check := map[string]bool{}
+	for _, inflator := range inflators {
+		if check[inflator] {
+			return ERR_INFLATORS_DUPLICATE(inflator)
+		}
+		check[inflator] = true
+	}
+	return nil
+}
+
+// decodeInflatorsDocument decodes the document, or the json array of the inflators
+// as the first version of the inflators
+func decodeInflatorsDocument(b []byte) (*InflatorsDocument, error) {
+	inflators := []string{}
+	if err := json.Unmarshal(b, &inflators); err == nil {
+		return &InflatorsDocument{Version: 1, Inflators: inflators}, nil
+	}
+	d := InflatorsDocument{}
+	err := json.Unmarshal(b, &d)
+	if err != nil {
+		return nil, errors.New("The json file for the inflators has not the correct JSON format: " + err.Error())
+	}
+	return &d, nil
+}
+
+// fetchInflatorsDocuments fetches the document of the hash and its previous versions until the first version,
+// and it verifies every version against its previous version. The documents are returned from the first version.
+// The root is needed for the later versions, because the first version that they lead to can be anyone's,
+// the hash of a first version is trusted without it.
+func fetchInflatorsDocuments(f Fetcher, hash, root string) ([]InflatorsDocument, error) {
+	docs := []InflatorsDocument{}
+	for {
+		b, err := f.Fetch(hash)
+		if err != nil {
+			return nil, errors.New("Failed to get the json file for the inflators " + hash + ": " + err.Error())
+		}
+		d, err := decodeInflatorsDocument(b)
+		if err != nil {
+			return nil, err
+		}
+		if len(docs) > 0 {
+			err = docs[0].Verify(d)
+			if err != nil {
+				return nil, errors.New("The inflators of the version " + fmt.Sprint(docs[0].Version) + " are not correct: " + err.Error())
+			}
+		}
+		if d.Version < 1 {
+			return nil, ERR_INFLATORS_VERSION_NOT_CORRECT
+		}
+		docs = append([]InflatorsDocument{*d}, docs...)
+		if d.Version > 1 {
+			if len(d.Previous) == 0 {
+				return nil, ERR_INFLATORS_PREVIOUS_MISSING
+			}
+			hash = d.Previous
+			continue
+		}
+		if len(d.Previous) > 0 {
+			return nil, ERR_INFLATORS_FIRST_HAS_PREVIOUS
+		}
+		if len(d.Inflators) == 0 {
+			return nil, ERR_INFLATORS_EMPTY
+		}
+		if len(root) == 0 && len(docs) > 1 {
+			return nil, ERR_INFLATORS_ROOT_MISSING
+		}
+		if len(root) > 0 && hash != root {
+			return nil, ERR_INFLATORS_ROOT_NOT_EQUAL(root)
+		}
+		return docs, nil
+	}
+}
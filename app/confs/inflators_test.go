@@ -0,0 +1,115 @@
+package confs
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/dedis/kyber/util/key"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// newInflatorsChain saves the first version of the inflators as a json array, and the next version
+// signed by the signers, in a content directory
+func newInflatorsChain(t *testing.T, next InflatorsDocument, signers []*key.Pair, signersPubHex []string, first []string) (string, string, string) {
+	firstB, _ := json.Marshal(first)
+	firstHash := ContentHash(firstB)
+	if len(next.Previous) == 0 {
+		next.Previous = firstHash
+	}
+	for i, signer := range signers {
+		assert.Nil(t, next.Sign(signersPubHex[i], signer.Private))
+	}
+	nextB, _ := json.Marshal(next)
+	nextHash := ContentHash(nextB)
+	dir := newContentDir(t, map[string][]byte{firstHash: firstB, nextHash: nextB})
+	return dir, firstHash, nextHash
+}
+
+func TestSetInflatorsFromHashRotatesTheInflators(t *testing.T) {
+	kp1, pub1 := utils.CreateKeyPair()
+	kp2, pub2 := utils.CreateKeyPair()
+	_, pub3 := utils.CreateKeyPair()
+	_, pub4 := utils.CreateKeyPair()
+	next := InflatorsDocument{Version: 2, ActivationHeight: 10, Inflators: []string{pub4}}
+	dir, firstHash, nextHash := newInflatorsChain(t, next, []*key.Pair{kp1, kp2}, []string{pub1, pub2}, []string{pub1, pub2, pub3})
+	defer os.RemoveAll(dir)
+
+	c := configuration{Fetcher: NewDirFetcher(dir), InflatorsRoot: firstHash}
+	err := c.SetInflatorsFromHash(nextHash)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(c.InflatorsDocuments))
+	assert.Equal(t, []string{pub4}, c.Inflators)
+	assert.Equal(t, []string{pub1, pub2, pub3}, c.GetInflators(9))
+	assert.Equal(t, []string{pub4}, c.GetInflators(10))
+}
+
+func TestSetInflatorsFromHashFailOnSignaturesNotEnough(t *testing.T) {
+	kp1, pub1 := utils.CreateKeyPair()
+	_, pub2 := utils.CreateKeyPair()
+	_, pub3 := utils.CreateKeyPair()
+	next := InflatorsDocument{Version: 2, ActivationHeight: 10, Inflators: []string{pub3}}
+	dir, _, nextHash := newInflatorsChain(t, next, []*key.Pair{kp1}, []string{pub1}, []string{pub1, pub2})
+	defer os.RemoveAll(dir)
+
+	c := configuration{Fetcher: NewDirFetcher(dir)}
+	err := c.SetInflatorsFromHash(nextHash)
+	assert.Equal(t, "The inflators of the version 2 are not correct: "+ERR_INFLATORS_SIGNATURES_NOT_ENOUGH(1, 2).Error(), err.Error())
+}
+
+func TestSetInflatorsFromHashFailOnVersionNotNext(t *testing.T) {
+	kp1, pub1 := utils.CreateKeyPair()
+	_, pub2 := utils.CreateKeyPair()
+	next := InflatorsDocument{Version: 3, ActivationHeight: 10, Inflators: []string{pub2}}
+	dir, _, nextHash := newInflatorsChain(t, next, []*key.Pair{kp1}, []string{pub1}, []string{pub1})
+	defer os.RemoveAll(dir)
+
+	c := configuration{Fetcher: NewDirFetcher(dir)}
+	err := c.SetInflatorsFromHash(nextHash)
+	assert.Equal(t, "The inflators of the version 3 are not correct: "+ERR_INFLATORS_VERSION_NOT_NEXT.Error(), err.Error())
+}
+
+func TestSetInflatorsFromHashFailOnRootNotEqual(t *testing.T) {
+	kp1, pub1 := utils.CreateKeyPair()
+	_, pub2 := utils.CreateKeyPair()
+	next := InflatorsDocument{Version: 2, ActivationHeight: 10, Inflators: []string{pub2}}
+	dir, _, nextHash := newInflatorsChain(t, next, []*key.Pair{kp1}, []string{pub1}, []string{pub1})
+	defer os.RemoveAll(dir)
+
+	root := ContentHash([]byte(`["abcd"]`))
+	c := configuration{Fetcher: NewDirFetcher(dir), InflatorsRoot: root}
+	err := c.SetInflatorsFromHash(nextHash)
+	assert.Equal(t, ERR_INFLATORS_ROOT_NOT_EQUAL(root), err)
+}
+
+func TestSetInflatorsFromHashFailOnRootMissing(t *testing.T) {
+	kp1, pub1 := utils.CreateKeyPair()
+	_, pub2 := utils.CreateKeyPair()
+	next := InflatorsDocument{Version: 2, ActivationHeight: 10, Inflators: []string{pub2}}
+	dir, firstHash, nextHash := newInflatorsChain(t, next, []*key.Pair{kp1}, []string{pub1}, []string{pub1})
+	defer os.RemoveAll(dir)
+
+	c := configuration{Fetcher: NewDirFetcher(dir)}
+	err := c.SetInflatorsFromHash(nextHash)
+	assert.Equal(t, ERR_INFLATORS_ROOT_MISSING, err)
+
+	// the first version is trusted by its own hash
+	err = c.SetInflatorsFromHash(firstHash)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{pub1}, c.Inflators)
+}
+
+func TestSetInflatorsFromHashFailOnDuplicatePreviousInflator(t *testing.T) {
+	kp1, pub1 := utils.CreateKeyPair()
+	_, pub2 := utils.CreateKeyPair()
+	_, pub3 := utils.CreateKeyPair()
+	next := InflatorsDocument{Version: 2, ActivationHeight: 10, Inflators: []string{pub3}}
+	// the signature of the one inflator would be counted twice of three
+	dir, firstHash, nextHash := newInflatorsChain(t, next, []*key.Pair{kp1}, []string{pub1}, []string{pub1, pub1, pub2})
+	defer os.RemoveAll(dir)
+
+	c := configuration{Fetcher: NewDirFetcher(dir), InflatorsRoot: firstHash}
+	err := c.SetInflatorsFromHash(nextHash)
+	assert.Equal(t, "The inflators of the version 2 are not correct: "+ERR_INFLATORS_DUPLICATE(pub1).Error(), err.Error())
+}
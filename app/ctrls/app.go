@@ -1,6 +1,7 @@
 package ctrls
 
 import (
+	"github.com/mragiadakos/tendermoney/app/confs"
 	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
 	"github.com/tendermint/abci/types"
 	dbm "github.com/tendermint/tmlibs/db"
@@ -10,10 +11,30 @@ var _ types.Application = (*TMApplication)(nil)
 
 type TMApplication struct {
 	types.BaseApplication
-	state dbpkg.State
+	state      dbpkg.State
+	checkState *dbpkg.State // the state of the mempool, over the latest state
+
+	// the powers of the validators that have been sent to tendermint, by their public keys
+	validators map[string]int64
 }
 
+// NewTMApplication loads the state from the database of the DBDir,
+// or it keeps the state in the memory when there is not a DBDir
 func NewTMApplication() *TMApplication {
-	state := dbpkg.LoadState(dbm.NewMemDB())
-	return &TMApplication{state: state}
+	var db dbm.DB
+	if len(confs.Conf.DBDir) > 0 {
+		db = dbm.NewDB("tendermoney", dbm.GoLevelDBBackend, confs.Conf.DBDir)
+	} else {
+		db = dbm.NewMemDB()
+	}
+	state := dbpkg.LoadState(db)
+	state.KeepVersions = confs.Conf.KeepVersions
+	app := &TMApplication{state: state}
+	app.resetCheckState()
+	app.validators = validatorPowers(state.GetValidators())
+	return app
+}
+
+func (app *TMApplication) resetCheckState() {
+	app.checkState = app.state.CacheWrap()
 }
@@ -0,0 +1,67 @@
+package ctrls
+
+import (
+	"encoding/hex"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/tendermint/abci/types"
+)
+
+// ED25519_PUBKEY_TYPE is the type of the public keys of the validators for tendermint
+const ED25519_PUBKEY_TYPE = "ed25519"
+
+// EndBlock unlocks the coins that their unbonding has finished, and it returns the validators
+// that the deliveries of the block have added, re-weighted or removed.
+// The validators are compared with the validators that have been sent to tendermint,
+// so a block that is replayed after a restart sends the same updates.
+func (app *TMApplication) EndBlock(req types.RequestEndBlock) types.ResponseEndBlock {
+	for _, coin := range app.state.ReleaseUnbonded(req.Height) {
+		err := app.state.UnlockCoin(coin)
+		if err != nil {
+			continue
+		}
+		app.state.AddCoinHistory(coin, dbpkg.COIN_UNBONDED, coinOwner(&app.state, coin), "")
+	}
+
+	updates := []types.Validator{}
+	svs := app.state.GetValidators()
+	// tendermint halts without validators, so the unstakes and the slashes that remove
+	// every voting power keep the validators that tendermint has
+	if totalVotingPower(svs) == 0 {
+		return types.ResponseEndBlock{ValidatorUpdates: updates}
+	}
+	for _, sv := range svs {
+		power, ok := app.validators[sv.PubKey]
+		if ok && power == sv.VotingPower() {
+			continue
+		}
+		// tendermint fails to remove the validators that it does not have, and a stake
+		// under one power does not make a validator. The validators of the genesis are in the state from InitChain.
+		if !ok && sv.VotingPower() == 0 {
+			continue
+		}
+		pub, _ := hex.DecodeString(sv.PubKey)
+		updates = append(updates, types.Validator{
+			PubKey: types.PubKey{Type: ED25519_PUBKEY_TYPE, Data: pub},
+			Power:  sv.VotingPower(),
+		})
+	}
+	app.validators = validatorPowers(svs)
+	return types.ResponseEndBlock{ValidatorUpdates: updates}
+}
+
+func totalVotingPower(svs []dbpkg.StateValidator) int64 {
+	total := int64(0)
+	for _, sv := range svs {
+		total += sv.VotingPower()
+	}
+	return total
+}
+
+func validatorPowers(svs []dbpkg.StateValidator) map[string]int64 {
+	powers := map[string]int64{}
+	for _, sv := range svs {
+		powers[sv.PubKey] = sv.VotingPower()
+	}
+	return powers
+}
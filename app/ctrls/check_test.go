@@ -0,0 +1,78 @@
+package ctrls
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dedis/kyber"
+	"github.com/dedis/kyber/group/edwards25519"
+	"github.com/dedis/kyber/proof/dleq"
+	"github.com/dedis/kyber/util/random"
+	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
+	"github.com/stretchr/testify/assert"
+)
+
+func sendTx(coins, fee []string, privs []kyber.Scalar) []byte {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	rng := random.New()
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+	proof, _, _, _ := dleq.NewDLEQProof(suite, g, h, x)
+
+	d := models.Delivery{}
+	d.Type = models.SEND
+	data := models.SendData{}
+	data.Coins = coins
+	data.Fee = fee
+	data.Proof = models.NewProof(proof)
+	d.Data = data
+	msg, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature(privs, msg)
+	b, _ := json.Marshal(d)
+	return b
+}
+
+func TestCheckFailOnDoubleSpendInMempool(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	createTax(t, app, inflatorKp, inflatorPubHex, 10)
+
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	fee1, fee1Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.1)
+	fee2, fee2Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.1)
+
+	resp := app.CheckTx(sendTx([]string{coin}, []string{fee1}, []kyber.Scalar{coinKp.Private, fee1Kp.Private}))
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	resp = app.CheckTx(sendTx([]string{coin}, []string{fee2}, []kyber.Scalar{coinKp.Private, fee2Kp.Private}))
+	assert.Equal(t, models.CodeTypeCoinLocked, resp.Code)
+	assert.Equal(t, validations.ERR_COIN_IS_LOCKED(coin).Error(), resp.Log)
+
+	// the check state does not change the state
+	locked, _ := app.state.IsCoinLocked(coin)
+	assert.False(t, locked)
+}
+
+func TestCheckStateResetOnCommit(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	createTax(t, app, inflatorKp, inflatorPubHex, 10)
+
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	fee, feeKp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.1)
+	tx := sendTx([]string{coin}, []string{fee}, []kyber.Scalar{coinKp.Private, feeKp.Private})
+
+	resp := app.CheckTx(tx)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	// the block did not include the transaction, so it is checked again after the commit
+	app.Commit()
+	resp = app.CheckTx(tx)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+}
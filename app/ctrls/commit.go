@@ -0,0 +1,19 @@
+package ctrls
+
+import (
+	"github.com/tendermint/abci/types"
+)
+
+func (app *TMApplication) Info(req types.RequestInfo) types.ResponseInfo {
+	return types.ResponseInfo{
+		LastBlockHeight:  app.state.Height,
+		LastBlockAppHash: app.state.AppHash,
+	}
+}
+
+func (app *TMApplication) Commit() types.ResponseCommit {
+	hash := app.state.Commit()
+	// the mempool is checked again by tendermint after the commit
+	app.resetCheckState()
+	return types.ResponseCommit{Data: hash}
+}
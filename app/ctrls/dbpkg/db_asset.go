@@ -0,0 +1,50 @@
+package dbpkg
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+)
+
+var (
+	assetKey = []byte("asset:")
+)
+
+var (
+	ERR_ASSET_DOES_NOT_EXIST = func(name string) error {
+		return errors.New("The asset " + name + " does not exist.")
+	}
+)
+
+func prefixAsset(name string) []byte {
+	return append(assetKey, []byte(name)...)
+}
+
+func (s *State) SetAsset(a models.Asset) {
+	b, _ := json.Marshal(a)
+	s.store.Set(prefixAsset(a.Name), b)
+}
+
+func (s *State) GetAsset(name string) (*models.Asset, error) {
+	has := s.store.Has(prefixAsset(name))
+	if !has {
+		return nil, ERR_ASSET_DOES_NOT_EXIST(name)
+	}
+	a := models.Asset{}
+	json.Unmarshal(s.store.Get(prefixAsset(name)), &a)
+	return &a, nil
+}
+
+// GetAssetDenominations returns the denominations of the asset,
+// or the denominations of the network when the asset does not have its own
+func (s *State) GetAssetDenominations(name string) []float64 {
+	if name == models.MAIN_ASSET {
+		return s.GetDenominations()
+	}
+	a, err := s.GetAsset(name)
+	if err != nil || len(a.Denominations) == 0 {
+		return s.GetDenominations()
+	}
+	return a.Denominations
+}
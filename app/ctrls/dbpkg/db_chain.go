@@ -0,0 +1,34 @@
+package dbpkg
+
+import "strconv"
+
+var (
+	chainIDKey        = []byte("chain_id")
+	lastJSONHeightKey = []byte("last_json_height")
+)
+
+// SetChainID saves the id of the chain from its genesis, that the binary deliveries sign
+func (s *State) SetChainID(chainID string) {
+	s.store.Set(chainIDKey, []byte(chainID))
+}
+
+// GetChainID returns the id of the chain, it is empty before the genesis
+func (s *State) GetChainID() string {
+	return string(s.store.Get(chainIDKey))
+}
+
+// SetLastJSONHeight saves the last height that accepts the json deliveries
+func (s *State) SetLastJSONHeight(height int64) {
+	s.store.Set(lastJSONHeightKey, []byte(strconv.FormatInt(height, 10)))
+}
+
+// GetLastJSONHeight returns the last height that accepts the json deliveries,
+// it is zero for the networks that accept them on every height
+func (s *State) GetLastJSONHeight() int64 {
+	has := s.store.Has(lastJSONHeightKey)
+	if !has {
+		return 0
+	}
+	height, _ := strconv.ParseInt(string(s.store.Get(lastJSONHeightKey)), 10, 64)
+	return height
+}
@@ -36,36 +36,49 @@ func prefixOwner(pub string) []byte {
 	return append(ownerKey, b...)
 }
 
+// CoinKey and OwnerKey are the keys of the coin and the owner in the state's tree
+func CoinKey(uuid string) []byte {
+	return prefixCoin(uuid)
+}
+
+func OwnerKey(pub string) []byte {
+	return prefixOwner(pub)
+}
+
 type StateCoin struct {
 	Coin     string
 	Owner    string
 	Value    float64
 	IsLocked bool
+	Asset    string `json:",omitempty"` // empty for the main currency
+	Height   int64  `json:",omitempty"` // the height that the coin has been created or its demurrage has been paid until
 }
 
 func (s *State) AddCoin(sc StateCoin) error {
-	has := s.db.Has(prefixCoin(sc.Coin))
+	has := s.store.Has(prefixCoin(sc.Coin))
 	if has {
 		return ERR_COIN_EXISTS_ALREADY(sc.Coin)
 	}
-	has = s.db.Has(prefixOwner(sc.Owner))
+	has = s.store.Has(prefixOwner(sc.Owner))
 	if has {
 		return ERR_OWNER_EXISTS_ALREADY(sc.Owner)
 	}
 
+	sc.Height = s.Height + 1
 	b, _ := json.Marshal(sc)
-	s.db.Set(prefixCoin(sc.Coin), b)
-	s.db.Set(prefixOwner(sc.Owner), []byte(sc.Coin))
+	s.store.Set(prefixCoin(sc.Coin), b)
+	s.store.Set(prefixOwner(sc.Owner), []byte(sc.Coin))
+	s.indexCoin(&sc)
 	return nil
 }
 
 func (s *State) GetCoin(uuid string) (*StateCoin, error) {
-	has := s.db.Has(prefixCoin(uuid))
+	has := s.store.Has(prefixCoin(uuid))
 	if !has {
 		return nil, ERR_COIN_DOES_NOT_EXISTS(uuid)
 	}
 	sc := new(StateCoin)
-	b := s.db.Get(prefixCoin(uuid))
+	b := s.store.Get(prefixCoin(uuid))
 	json.Unmarshal(b, &sc)
 	return sc, nil
 }
@@ -75,28 +88,31 @@ func (s *State) DeleteCoinAndOwner(uuid string) {
 	if err != nil {
 		return
 	}
-	s.db.Delete(prefixCoin(uuid))
-	s.db.Delete(prefixOwner(sc.Owner))
+	s.store.Delete(prefixCoin(uuid))
+	s.store.Delete(prefixOwner(sc.Owner))
+	s.unindexCoin(sc)
 }
 
 func (s *State) GetOwner(pubHex string) (string, error) {
-	has := s.db.Has(prefixOwner(pubHex))
+	has := s.store.Has(prefixOwner(pubHex))
 	if !has {
 		return "", ERR_OWNER_DOES_NOT_EXISTS(pubHex)
 	}
-	b := s.db.Get(prefixOwner(pubHex))
+	b := s.store.Get(prefixOwner(pubHex))
 	return string(b), nil
 }
 
 func (s *State) DeleteOwner(pubHex string) error {
-	has := s.db.Has(prefixOwner(pubHex))
+	has := s.store.Has(prefixOwner(pubHex))
 	if !has {
 		return ERR_OWNER_DOES_NOT_EXISTS(pubHex)
 	}
-	s.db.Delete(prefixOwner(pubHex))
+	s.store.Delete(prefixOwner(pubHex))
 	return nil
 }
 
+// SetNewOwner changes the owner of the coin, the coin keeps its height,
+// so the new owner pays the demurrage that has not been paid
 func (s *State) SetNewOwner(uuid, owner string) error {
 	sc, err := s.GetCoin(uuid)
 	if err != nil {
@@ -104,18 +120,31 @@ func (s *State) SetNewOwner(uuid, owner string) error {
 	}
 	sc.Owner = owner
 	b, _ := json.Marshal(sc)
-	s.db.Set(prefixCoin(sc.Coin), b)
-	s.db.Set(prefixOwner(sc.Owner), []byte(sc.Coin))
+	s.store.Set(prefixCoin(sc.Coin), b)
+	s.store.Set(prefixOwner(sc.Owner), []byte(sc.Coin))
+	return nil
+}
+
+// SetDemurragePaid sets the height of the coin to the current block,
+// because the delivery of the block has paid its demurrage
+func (s *State) SetDemurragePaid(uuid string) error {
+	sc, err := s.GetCoin(uuid)
+	if err != nil {
+		return err
+	}
+	sc.Height = s.Height + 1
+	b, _ := json.Marshal(sc)
+	s.store.Set(prefixCoin(sc.Coin), b)
 	return nil
 }
 
 func (s *State) IsCoinLocked(uuid string) (bool, error) {
-	has := s.db.Has(prefixCoin(uuid))
+	has := s.store.Has(prefixCoin(uuid))
 	if !has {
 		return false, ERR_COIN_DOES_NOT_EXISTS(uuid)
 	}
 	sc := new(StateCoin)
-	b := s.db.Get(prefixCoin(uuid))
+	b := s.store.Get(prefixCoin(uuid))
 	json.Unmarshal(b, &sc)
 	return sc.IsLocked, nil
 }
@@ -127,7 +156,8 @@ func (s *State) LockCoin(uuid string) error {
 	}
 	sc.IsLocked = true
 	b, _ := json.Marshal(sc)
-	s.db.Set(prefixCoin(sc.Coin), b)
+	s.store.Set(prefixCoin(sc.Coin), b)
+	s.indexCoin(sc)
 	return nil
 }
 
@@ -138,6 +168,7 @@ func (s *State) UnlockCoin(uuid string) error {
 	}
 	sc.IsLocked = false
 	b, _ := json.Marshal(sc)
-	s.db.Set(prefixCoin(sc.Coin), b)
+	s.store.Set(prefixCoin(sc.Coin), b)
+	s.indexCoin(sc)
 	return nil
 }
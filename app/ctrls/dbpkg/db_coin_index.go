@@ -0,0 +1,118 @@
+package dbpkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// The secondary indexes of the coins, they change together with the coins
+var (
+	coinValueKey   = []byte("coin_value:")
+	lockedCoinKey  = []byte("locked_coin:")
+	coinHistoryKey = []byte("coin_history:")
+)
+
+// The actions of the coin's history
+const (
+	COIN_INFLATED            = "inflated"
+	COIN_SUMMED              = "summed"
+	COIN_CREATED_BY_SUM      = "created_by_sum"
+	COIN_DIVIDED             = "divided"
+	COIN_CREATED_BY_DIVISION = "created_by_division"
+	COIN_SENT                = "sent"
+	COIN_SENT_TO_STEALTH     = "sent_to_stealth"
+	COIN_RECEIVED            = "received"
+	COIN_RECEIVED_AS_FEE     = "received_as_fee"
+	COIN_CREATED_IN_GENESIS  = "created_in_genesis"
+	COIN_STAKED              = "staked"
+	COIN_UNSTAKED            = "unstaked"
+	COIN_UNBONDED            = "unbonded"
+	COIN_SLASHED             = "slashed"
+	COIN_PAID_DEMURRAGE      = "paid_demurrage"
+)
+
+// prefixCoinValue has the asset, because the coins of different assets can have the same value
+func prefixCoinValue(asset string, value float64) []byte {
+	return []byte(string(coinValueKey) + asset + ":" + strconv.FormatFloat(value, 'f', -1, 64) + ":")
+}
+
+func prefixLockedCoin(uuid string) []byte {
+	return []byte(string(lockedCoinKey) + uuid)
+}
+
+func prefixCoinHistory(uuid string) []byte {
+	return []byte(string(coinHistoryKey) + uuid + ":")
+}
+
+func (s *State) indexCoin(sc *StateCoin) {
+	s.store.Set(append(prefixCoinValue(sc.Asset, sc.Value), []byte(sc.Coin)...), []byte(sc.Coin))
+	if sc.IsLocked {
+		s.store.Set(prefixLockedCoin(sc.Coin), []byte(sc.Coin))
+	} else {
+		s.store.Delete(prefixLockedCoin(sc.Coin))
+	}
+}
+
+func (s *State) unindexCoin(sc *StateCoin) {
+	s.store.Delete(append(prefixCoinValue(sc.Asset, sc.Value), []byte(sc.Coin)...))
+	s.store.Delete(prefixLockedCoin(sc.Coin))
+}
+
+// iterateIndex iterates the coins of the index in the order of their UUID,
+// starting after the UUID of the cursor, until the fn returns false
+func (s *State) iterateIndex(prefix []byte, after string, fn func(uuid string) bool) {
+	start := prefix
+	if len(after) > 0 {
+		start = append([]byte(string(prefix)+after), 0x00)
+	}
+	s.store.IterateRange(start, prefixEnd(prefix), func(key, value []byte) bool {
+		return fn(string(value))
+	})
+}
+
+func (s *State) IterateLockedCoins(after string, fn func(uuid string) bool) {
+	s.iterateIndex(lockedCoinKey, after, fn)
+}
+
+func (s *State) IterateCoinsByValue(asset string, value float64, after string, fn func(uuid string) bool) {
+	s.iterateIndex(prefixCoinValue(asset, value), after, fn)
+}
+
+// CoinHistory is what happened to the coin on a height
+type CoinHistory struct {
+	Height        int64
+	Action        string
+	PreviousOwner string
+	NewOwner      string
+}
+
+// AddCoinHistory appends the action to the history of the coin, the history is never deleted
+func (s *State) AddCoinHistory(uuid, action, previousOwner, newOwner string) {
+	prefix := prefixCoinHistory(uuid)
+	index := 0
+	s.store.IteratePrefix(prefix, func(key, value []byte) bool {
+		index++
+		return true
+	})
+	ch := CoinHistory{
+		Height:        s.Height + 1,
+		Action:        action,
+		PreviousOwner: previousOwner,
+		NewOwner:      newOwner,
+	}
+	b, _ := json.Marshal(ch)
+	// the padding keeps the history in order
+	s.store.Set(append(prefix, []byte(fmt.Sprintf("%010d", index))...), b)
+}
+
+func (s *State) GetCoinHistory(uuid string) []CoinHistory {
+	chs := []CoinHistory{}
+	s.store.IteratePrefix(prefixCoinHistory(uuid), func(key, value []byte) bool {
+		ch := CoinHistory{}
+		json.Unmarshal(value, &ch)
+		chs = append(chs, ch)
+		return true
+	})
+	return chs
+}
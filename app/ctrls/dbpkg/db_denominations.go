@@ -0,0 +1,30 @@
+package dbpkg
+
+import (
+	"encoding/json"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+)
+
+var (
+	denominationsKey = []byte("denominations")
+)
+
+// SetDenominations saves the values that the coins of the network can have
+func (s *State) SetDenominations(denominations []float64) {
+	b, _ := json.Marshal(denominations)
+	s.store.Set(denominationsKey, b)
+}
+
+// GetDenominations returns the values that the coins of the network can have,
+// the networks without denominations in their genesis use a copy of the CONSTANT_VALUES
+func (s *State) GetDenominations() []float64 {
+	has := s.store.Has(denominationsKey)
+	if !has {
+		return append([]float64{}, models.CONSTANT_VALUES...)
+	}
+	b := s.store.Get(denominationsKey)
+	ds := []float64{}
+	json.Unmarshal(b, &ds)
+	return ds
+}
@@ -0,0 +1,91 @@
+package dbpkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+var (
+	slashKey           = []byte("slash:")
+	slashPercentageKey = []byte("slash_percentage")
+)
+
+// DEFAULT_SLASH_PERCENTAGE is the percentage of the stake that a misbehaving validator loses,
+// for the networks without slash percentage in their genesis
+const DEFAULT_SLASH_PERCENTAGE = 5
+
+// StateSlash is the confiscation of the staked coins of a validator, for the evidence of its misbehavior.
+// The coins are the fee of the transaction with the Hash, so the inflators can retrieve them.
+type StateSlash struct {
+	Hash           string `json:",omitempty"`
+	Validator      string
+	Height         int64 // the height of the block that slashed the validator
+	EvidenceHeight int64 // the height of the misbehavior
+	Type           string
+	Percentage     int
+	Coins          []string
+	Value          float64
+}
+
+// SlashHash is the hash of the transaction with the confiscated coins of the validator
+func SlashHash(validator string, evidenceHeight int64) string {
+	hash := sha256.Sum256([]byte(string(slashKey) + validator + ":" + strconv.FormatInt(evidenceHeight, 10)))
+	return hex.EncodeToString(hash[:])
+}
+
+func prefixSlash(validator string) []byte {
+	return []byte(string(slashKey) + validator + ":")
+}
+
+// the padding keeps the slashes of the validator in the order of the evidence's height
+func slashKeyOf(validator string, evidenceHeight int64) []byte {
+	return append(prefixSlash(validator), []byte(fmt.Sprintf("%020d", evidenceHeight))...)
+}
+
+// AddSlash saves the slash, and its coins as the fee of a transaction that the inflators can retrieve
+func (s *State) AddSlash(sl StateSlash) {
+	b, _ := json.Marshal(sl)
+	s.store.Set(slashKeyOf(sl.Validator, sl.EvidenceHeight), b)
+	if len(sl.Coins) > 0 {
+		s.AddFeeTransaction(sl.Hash, sl.Coins)
+	}
+}
+
+// HasSlash returns if the validator has been slashed for the misbehavior of the height
+func (s *State) HasSlash(validator string, evidenceHeight int64) bool {
+	return s.store.Has(slashKeyOf(validator, evidenceHeight))
+}
+
+// GetSlashes returns the slashes of the validator, or of all the validators when it is empty
+func (s *State) GetSlashes(validator string) []StateSlash {
+	prefix := slashKey
+	if len(validator) > 0 {
+		prefix = prefixSlash(validator)
+	}
+	sls := []StateSlash{}
+	s.store.IteratePrefix(prefix, func(key, value []byte) bool {
+		sl := StateSlash{}
+		json.Unmarshal(value, &sl)
+		sls = append(sls, sl)
+		return true
+	})
+	return sls
+}
+
+// SetSlashPercentage saves the percentage of the stake that a misbehaving validator loses
+func (s *State) SetSlashPercentage(percentage int) {
+	s.store.Set(slashPercentageKey, []byte(strconv.Itoa(percentage)))
+}
+
+// GetSlashPercentage returns the percentage of the stake that a misbehaving validator loses
+func (s *State) GetSlashPercentage() int {
+	has := s.store.Has(slashPercentageKey)
+	if !has {
+		return DEFAULT_SLASH_PERCENTAGE
+	}
+	percentage, _ := strconv.Atoi(string(s.store.Get(slashPercentageKey)))
+	return percentage
+}
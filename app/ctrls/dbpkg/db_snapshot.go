@@ -0,0 +1,186 @@
+package dbpkg
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/tendermint/iavl"
+	dbm "github.com/tendermint/tmlibs/db"
+)
+
+// SNAPSHOT_VERSION is the version of the format of the snapshots,
+// the snapshots without the proof of their items are not supported
+const SNAPSHOT_VERSION = 2
+
+var (
+	ERR_SNAPSHOT_VERSION_NOT_SUPPORTED = func(version int) error {
+		return errors.New("The version " + strconv.Itoa(version) + " of the snapshot is not supported.")
+	}
+	ERR_SNAPSHOT_HASH_NOT_EQUAL     = errors.New("The hash of the imported state is not equal to the hash of the snapshot.")
+	ERR_STATE_IS_NOT_EMPTY          = errors.New("The snapshot can only be imported to an empty state.")
+	ERR_SNAPSHOT_APP_HASH_NOT_EQUAL = errors.New("The app hash of the snapshot is not equal to the trusted app hash.")
+	ERR_SNAPSHOT_PROOF_MISSING      = errors.New("The proof of the snapshot is missing.")
+	ERR_SNAPSHOT_PROOF_NOT_VALID    = func(err error) error {
+		return errors.New("The proof of the snapshot does not match with its app hash: " + err.Error())
+	}
+	ERR_SNAPSHOT_ITEMS_NOT_EQUAL_TO_PROOF = errors.New("The items of the snapshot are not all the keys of the proof.")
+	ERR_SNAPSHOT_ITEM_NOT_PROVEN          = func(key []byte) error {
+		return errors.New("The key " + string(key) + " of the snapshot is not proven by its proof.")
+	}
+)
+
+// SnapshotItem is a key of the state with its value
+type SnapshotItem struct {
+	Key   []byte
+	Value []byte
+}
+
+// Snapshot is the state of a height, that can be imported to a new state.
+// The shape of the tree depends on the order of the keys, so the imported state
+// does not have the app hash of the exported height, but the Hash of the snapshot.
+// The items are proven to the AppHash with the Proof, so the AppHash is the only one that needs to be trusted.
+type Snapshot struct {
+	Version      int
+	Height       int64            // the height that has been exported
+	AppHash      []byte           // the app hash of the height that has been exported
+	Hash         []byte           // the app hash of the state after the import
+	Proof        *iavl.RangeProof // the proof of all the keys of the height to the AppHash
+	Inflators    []string
+	Coins        []SnapshotItem
+	Owners       []SnapshotItem
+	Transactions []SnapshotItem
+	Taxes        []SnapshotItem
+	Indexes      []SnapshotItem // the indexes, the assets and the history of the coins
+}
+
+func (snap *Snapshot) add(item SnapshotItem) {
+	switch {
+	case bytes.HasPrefix(item.Key, coinKey):
+		snap.Coins = append(snap.Coins, item)
+	case bytes.HasPrefix(item.Key, ownerKey):
+		snap.Owners = append(snap.Owners, item)
+	case bytes.HasPrefix(item.Key, transactionKey):
+		snap.Transactions = append(snap.Transactions, item)
+	case bytes.HasPrefix(item.Key, latestTax):
+		snap.Taxes = append(snap.Taxes, item)
+	default:
+		snap.Indexes = append(snap.Indexes, item)
+	}
+}
+
+// items returns all the items of the snapshot in the order of their keys
+func (snap *Snapshot) items() []SnapshotItem {
+	items := []SnapshotItem{}
+	items = append(items, snap.Coins...)
+	items = append(items, snap.Owners...)
+	items = append(items, snap.Transactions...)
+	items = append(items, snap.Taxes...)
+	items = append(items, snap.Indexes...)
+	sort.Slice(items, func(i, j int) bool { return bytes.Compare(items[i].Key, items[j].Key) < 0 })
+	return items
+}
+
+// Export returns the snapshot of the state at the version
+func (s *State) Export(version int64) (*Snapshot, error) {
+	if version == 0 {
+		version = s.Height
+	}
+	if _, err := s.AtVersion(version); err != nil {
+		return nil, err
+	}
+	keys, values, proof, err := s.tree.GetVersionedRangeWithProof(nil, nil, 0, version)
+	if err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{Version: SNAPSHOT_VERSION, Height: version, AppHash: proof.ComputeRootHash(), Proof: proof}
+	for i := range keys {
+		snap.add(SnapshotItem{Key: keys[i], Value: values[i]})
+	}
+	snap.Hash = snap.ImportedHash()
+	return snap, nil
+}
+
+// ImportedHash returns the app hash that the state has after the import of the snapshot
+func (snap *Snapshot) ImportedHash() []byte {
+	imported := LoadState(dbm.NewMemDB())
+	for _, item := range snap.items() {
+		imported.tree.Set(item.Key, item.Value)
+	}
+	return imported.tree.Hash()
+}
+
+// verifyProof verifies that the items of the snapshot are all the keys of the state with the AppHash
+func (snap *Snapshot) verifyProof() error {
+	if snap.Proof == nil {
+		return ERR_SNAPSHOT_PROOF_MISSING
+	}
+	err := snap.Proof.Verify(snap.AppHash)
+	if err != nil {
+		return ERR_SNAPSHOT_PROOF_NOT_VALID(err)
+	}
+	items := snap.items()
+	if len(items) != len(snap.Proof.Keys()) {
+		return ERR_SNAPSHOT_ITEMS_NOT_EQUAL_TO_PROOF
+	}
+	for i, item := range items {
+		// the keys are unique, so the same number of keys are all the keys of the proof
+		if i > 0 && bytes.Equal(items[i-1].Key, item.Key) {
+			return ERR_SNAPSHOT_ITEMS_NOT_EQUAL_TO_PROOF
+		}
+		err = snap.Proof.VerifyItem(item.Key, item.Value)
+		if err != nil {
+			return ERR_SNAPSHOT_ITEM_NOT_PROVEN(item.Key)
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	// the proof starts from the first key of the tree and it ends on the last key of the tree,
+	// the keys of the state are text, so the zero byte is before all of them
+	err = snap.Proof.VerifyAbsence([]byte{0})
+	if err != nil {
+		return ERR_SNAPSHOT_PROOF_NOT_VALID(err)
+	}
+	err = snap.Proof.VerifyAbsence(append(append([]byte{}, items[len(items)-1].Key...), 0))
+	if err != nil {
+		return ERR_SNAPSHOT_PROOF_NOT_VALID(err)
+	}
+	return nil
+}
+
+// Import sets the keys of the snapshot to the empty state, in the order of the keys,
+// after the keys have been verified with the proof against the AppHash of the snapshot.
+// The keys are saved on the next commit. When the hash of the state is not the hash
+// of the snapshot, the keys are removed and it fails.
+func (s *State) Import(snap *Snapshot) error {
+	if snap.Version != SNAPSHOT_VERSION {
+		return ERR_SNAPSHOT_VERSION_NOT_SUPPORTED(snap.Version)
+	}
+	if s.Height > 0 || s.tree.Size() > 0 {
+		return ERR_STATE_IS_NOT_EMPTY
+	}
+	err := snap.verifyProof()
+	if err != nil {
+		return err
+	}
+	for _, item := range snap.items() {
+		s.tree.Set(item.Key, item.Value)
+	}
+	if !bytes.Equal(s.tree.Hash(), snap.Hash) {
+		s.tree.Rollback()
+		return ERR_SNAPSHOT_HASH_NOT_EQUAL
+	}
+	return nil
+}
+
+// VerifySnapshot checks that the snapshot is of the trusted app hash,
+// and it imports the snapshot to an empty state in the memory, to check its proof and its hash
+func VerifySnapshot(snap *Snapshot, appHash []byte) error {
+	if !bytes.Equal(snap.AppHash, appHash) {
+		return ERR_SNAPSHOT_APP_HASH_NOT_EQUAL
+	}
+	state := LoadState(dbm.NewMemDB())
+	return state.Import(snap)
+}
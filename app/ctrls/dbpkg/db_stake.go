@@ -0,0 +1,148 @@
+package dbpkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	stakeKey           = []byte("stake:")
+	stakedCoinKey      = []byte("staked_coin:")
+	unbondingKey       = []byte("unbonding:")
+	unbondingBlocksKey = []byte("unbonding_blocks")
+)
+
+// DEFAULT_UNBONDING_BLOCKS is how many blocks the unstaked coins stay locked,
+// for the networks without unbonding blocks in their genesis
+const DEFAULT_UNBONDING_BLOCKS = 100
+
+var (
+	ERR_STAKE_DOES_NOT_EXIST = func(uuid string) error {
+		return errors.New("The coin " + uuid + " has not been staked.")
+	}
+)
+
+// StateStake is a coin that backs the voting power of the validator
+type StateStake struct {
+	Coin            string
+	Validator       string
+	Value           float64
+	UnbondingHeight int64 `json:",omitempty"` // the height that the coin is unlocked, zero while it is staked
+}
+
+func prefixStake(validator string) []byte {
+	return []byte(string(stakeKey) + validator + ":")
+}
+
+func prefixStakedCoin(uuid string) []byte {
+	return []byte(string(stakedCoinKey) + uuid)
+}
+
+// the padding keeps the unbonding coins in the order of their height
+func prefixUnbonding(height int64) []byte {
+	return []byte(string(unbondingKey) + fmt.Sprintf("%020d", height) + ":")
+}
+
+func (s *State) setStake(ss StateStake) {
+	b, _ := json.Marshal(ss)
+	s.store.Set(append(prefixStake(ss.Validator), []byte(ss.Coin)...), b)
+	s.store.Set(prefixStakedCoin(ss.Coin), []byte(ss.Validator))
+}
+
+// AddStake stakes the coin to the validator and adds its value to the validator's stake
+func (s *State) AddStake(ss StateStake) {
+	s.setStake(ss)
+	s.addValidatorStake(ss.Validator, ss.Value)
+}
+
+func (s *State) GetStake(uuid string) (*StateStake, error) {
+	has := s.store.Has(prefixStakedCoin(uuid))
+	if !has {
+		return nil, ERR_STAKE_DOES_NOT_EXIST(uuid)
+	}
+	validator := string(s.store.Get(prefixStakedCoin(uuid)))
+	ss := StateStake{}
+	json.Unmarshal(s.store.Get(append(prefixStake(validator), []byte(uuid)...)), &ss)
+	return &ss, nil
+}
+
+// GetValidatorStakes returns the coins that have been staked to the validator, in the order of their UUID
+func (s *State) GetValidatorStakes(validator string) []StateStake {
+	sss := []StateStake{}
+	s.store.IteratePrefix(prefixStake(validator), func(key, value []byte) bool {
+		ss := StateStake{}
+		json.Unmarshal(value, &ss)
+		sss = append(sss, ss)
+		return true
+	})
+	return sss
+}
+
+// StartUnbonding removes the value of the coin from the validator's stake,
+// and the coin stays locked until the height
+func (s *State) StartUnbonding(uuid string, height int64) error {
+	ss, err := s.GetStake(uuid)
+	if err != nil {
+		return err
+	}
+	ss.UnbondingHeight = height
+	s.setStake(*ss)
+	s.store.Set(append(prefixUnbonding(height), []byte(uuid)...), []byte(uuid))
+	s.addValidatorStake(ss.Validator, -ss.Value)
+	return nil
+}
+
+// ReleaseUnbonded deletes the stakes that their unbonding has finished until the height,
+// and it returns their coins in the order of the height and the UUID
+func (s *State) ReleaseUnbonded(height int64) []string {
+	keys := [][]byte{}
+	coins := []string{}
+	s.store.IterateRange(unbondingKey, prefixUnbonding(height+1), func(key, value []byte) bool {
+		keys = append(keys, key)
+		coins = append(coins, string(value))
+		return true
+	})
+	for i, coin := range coins {
+		ss, err := s.GetStake(coin)
+		if err == nil {
+			s.store.Delete(append(prefixStake(ss.Validator), []byte(coin)...))
+		}
+		s.store.Delete(prefixStakedCoin(coin))
+		s.store.Delete(keys[i])
+	}
+	return coins
+}
+
+// ConfiscateStake deletes the stake of the coin and removes its value from the validator's stake,
+// the coin stays locked until it is retrieved
+func (s *State) ConfiscateStake(uuid string) error {
+	ss, err := s.GetStake(uuid)
+	if err != nil {
+		return err
+	}
+	s.store.Delete(append(prefixStake(ss.Validator), []byte(uuid)...))
+	s.store.Delete(prefixStakedCoin(uuid))
+	if ss.UnbondingHeight > 0 {
+		s.store.Delete(append(prefixUnbonding(ss.UnbondingHeight), []byte(uuid)...))
+	} else {
+		s.addValidatorStake(ss.Validator, -ss.Value)
+	}
+	return nil
+}
+
+// SetUnbondingBlocks saves how many blocks the unstaked coins stay locked
+func (s *State) SetUnbondingBlocks(blocks int64) {
+	s.store.Set(unbondingBlocksKey, []byte(strconv.FormatInt(blocks, 10)))
+}
+
+// GetUnbondingBlocks returns how many blocks the unstaked coins stay locked
+func (s *State) GetUnbondingBlocks() int64 {
+	has := s.store.Has(unbondingBlocksKey)
+	if !has {
+		return DEFAULT_UNBONDING_BLOCKS
+	}
+	blocks, _ := strconv.ParseInt(string(s.store.Get(unbondingBlocksKey)), 10, 64)
+	return blocks
+}
@@ -2,25 +2,70 @@ package dbpkg
 
 import (
 	"encoding/json"
+	"strconv"
 
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 )
 
 var (
-	latestTax = []byte("latestTax")
+	latestTax       = []byte("latestTax")
+	demurrageHeight = []byte("demurrageHeight")
 )
 
+// AssetTaxKey is the key of the latest tax of the asset in the state's tree
+func AssetTaxKey(asset string) []byte {
+	if asset == models.MAIN_ASSET {
+		return latestTax
+	}
+	return append(append([]byte{}, latestTax...), []byte(":"+asset)...)
+}
+
+func demurrageHeightKey(asset string) []byte {
+	return append(append([]byte{}, demurrageHeight...), []byte(":"+asset)...)
+}
+
+// AddTax saves the latest tax of the asset. A new demurrage is counted from the next block,
+// so the coins are not charged the new demurrage for the blocks before it.
 func (s *State) AddTax(tax models.TaxData) {
+	if s.GetAssetTax(tax.Asset).Demurrage != tax.Demurrage {
+		s.store.Set(demurrageHeightKey(tax.Asset), []byte(strconv.FormatInt(s.Height+1, 10)))
+	}
 	b, _ := json.Marshal(tax)
-	s.db.Set(latestTax, b)
+	s.store.Set(AssetTaxKey(tax.Asset), b)
 }
 
+// GetDemurrageHeight returns the height that the latest demurrage of the asset is counted from
+func (s *State) GetDemurrageHeight(asset string) int64 {
+	has := s.store.Has(demurrageHeightKey(asset))
+	if !has {
+		return 0
+	}
+	height, _ := strconv.ParseInt(string(s.store.Get(demurrageHeightKey(asset))), 10, 64)
+	return height
+}
+
+// DemurrageBlocks returns the blocks that the coin has been held for, until the current block,
+// since it has been created, its demurrage has been paid or the demurrage of its asset has changed
+func (s *State) DemurrageBlocks(sc *StateCoin) int64 {
+	from := sc.Height
+	if dh := s.GetDemurrageHeight(sc.Asset); dh > from {
+		from = dh
+	}
+	return s.Height + 1 - from
+}
+
+// GetTax returns the latest tax of the main currency
 func (s *State) GetTax() models.TaxData {
-	has := s.db.Has(latestTax)
+	return s.GetAssetTax(models.MAIN_ASSET)
+}
+
+// GetAssetTax returns the latest tax of the asset
+func (s *State) GetAssetTax(asset string) models.TaxData {
+	has := s.store.Has(AssetTaxKey(asset))
 	if !has {
 		return models.TaxData{}
 	}
-	b := s.db.Get(latestTax)
+	b := s.store.Get(AssetTaxKey(asset))
 	td := models.TaxData{}
 	json.Unmarshal(b, &td)
 	return td
@@ -5,7 +5,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"strings"
+	"strconv"
 
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 )
@@ -14,6 +14,9 @@ var (
 	ERR_TRANSACTION_NOT_EXIST = func(hash string) error {
 		return errors.New("The transaction " + hash + " does not exists.")
 	}
+	ERR_TRANSACTION_EXISTS_ALREADY = func(hash string) error {
+		return errors.New("The transaction " + hash + " exists already.")
+	}
 )
 var (
 	transactionKey = []byte("transaction:")
@@ -24,42 +27,92 @@ func prefixTransaction(hashHex string) []byte {
 	return append(transactionKey, []byte(hashHex)...)
 }
 
+// TransactionKey is the key of the transaction in the state's tree
+func TransactionKey(hashHex string) []byte {
+	return prefixTransaction(hashHex)
+}
+
 type StateTransaction struct {
 	models.SendData
-	IsCoinsReceived bool // the coins retrieved by the receiver
-	IsFeeReceived   bool // the fee retrieved by the inflator
+	ReceivedCoins   []string // the coins retrieved by the receiver until now
+	IsCoinsReceived bool     // all the coins retrieved by the receiver
+	IsFeeReceived   bool     // the fee retrieved by the inflator
+	Height          int64    // the height of the block that delivered the transaction
+}
+
+func (st *StateTransaction) IsCoinReceived(uuid string) bool {
+	for _, v := range st.ReceivedCoins {
+		if v == uuid {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionHash is the hash of the transaction that sends the coins
+func TransactionHash(coins []string) string {
+	coinb, _ := json.Marshal(coins)
+	hash := sha256.Sum256(coinb)
+	return hex.EncodeToString(hash[:])
 }
 
 func (s *State) AddTransaction(sd models.SendData) error {
 	st := StateTransaction{}
 	st.SendData = sd
+	st.Height = s.Height + 1
+	// the stealth owners own the coins from the send
+	if sd.IsStealth() {
+		st.ReceivedCoins = sd.Coins
+		st.IsCoinsReceived = true
+	}
 	sdb, _ := json.Marshal(st)
-	coinb, _ := json.Marshal(sd.Coins)
-	hash := sha256.Sum256(coinb)
-	hashHex := hex.EncodeToString(hash[:])
-	s.db.Set(prefixTransaction(hashHex), sdb)
+	s.store.Set(prefixTransaction(TransactionHash(sd.Coins)), sdb)
 	return nil
 }
 
+// AddFeeTransaction saves the coins of the fee as a transaction that the inflators retrieve,
+// for the fees that are not paid by a send
+func (s *State) AddFeeTransaction(hash string, fee []string) {
+	st := StateTransaction{}
+	st.Fee = fee
+	st.IsCoinsReceived = true
+	st.Height = s.Height + 1
+	stb, _ := json.Marshal(st)
+	s.store.Set(prefixTransaction(hash), stb)
+}
+
+// DemurrageHash is the hash of the transaction with the fee that the coins of a sum or a division
+// have paid for their demurrage, with the height of the block, because the uuids of the coins can be used again
+func DemurrageHash(coins []string, height int64) string {
+	msg, _ := json.Marshal(coins)
+	hash := sha256.Sum256(append([]byte("demurrage:"+strconv.FormatInt(height, 10)+":"), msg...))
+	return hex.EncodeToString(hash[:])
+}
+
 func (s *State) GetTransaction(hash string) (*StateTransaction, error) {
-	has := s.db.Has(prefixTransaction(hash))
+	has := s.store.Has(prefixTransaction(hash))
 	if !has {
 		return nil, ERR_TRANSACTION_NOT_EXIST(hash)
 	}
-	b := s.db.Get(prefixTransaction(hash))
+	b := s.store.Get(prefixTransaction(hash))
 	st := new(StateTransaction)
 	json.Unmarshal(b, &st)
 	return st, nil
 }
 
-func (s *State) CoinsReceivedFromTransaction(hash string) error {
+func (s *State) CoinsReceivedFromTransaction(hash string, coins []string) error {
 	st, err := s.GetTransaction(hash)
 	if err != nil {
 		return err
 	}
-	st.IsCoinsReceived = true
+	for _, coin := range coins {
+		if !st.IsCoinReceived(coin) {
+			st.ReceivedCoins = append(st.ReceivedCoins, coin)
+		}
+	}
+	st.IsCoinsReceived = len(st.ReceivedCoins) == len(st.Coins)
 	stb, _ := json.Marshal(st)
-	s.db.Set(prefixTransaction(hash), stb)
+	s.store.Set(prefixTransaction(hash), stb)
 	return nil
 }
 
@@ -70,23 +123,32 @@ func (s *State) FeeRetrievedFromTransaction(hash string) error {
 	}
 	st.IsFeeReceived = true
 	stb, _ := json.Marshal(st)
-	s.db.Set(prefixTransaction(hash), stb)
+	s.store.Set(prefixTransaction(hash), stb)
 	return nil
 }
 
 func (s *State) GetTransactions() []StateTransaction {
-	iter := s.db.Iterator(nil, nil)
 	sts := []StateTransaction{}
-	for {
-		if !iter.Valid() {
-			break
-		}
-		if strings.HasPrefix(string(iter.Key()), string(transactionKey)) {
-			st := StateTransaction{}
-			json.Unmarshal(iter.Value(), &st)
-			sts = append(sts, st)
-		}
-		iter.Next()
-	}
+	s.store.IteratePrefix(transactionKey, func(key, value []byte) bool {
+		st := StateTransaction{}
+		json.Unmarshal(value, &st)
+		sts = append(sts, st)
+		return true
+	})
 	return sts
 }
+
+// IterateTransactions iterates the transactions in the order of their hash,
+// starting after the hash of the cursor, until the fn returns false.
+// The empty cursor starts from the first transaction.
+func (s *State) IterateTransactions(after string, fn func(hash string, st *StateTransaction) bool) {
+	start := transactionKey
+	if len(after) > 0 {
+		start = append(prefixTransaction(after), 0x00)
+	}
+	s.store.IterateRange(start, prefixEnd(transactionKey), func(key, value []byte) bool {
+		st := StateTransaction{}
+		json.Unmarshal(value, &st)
+		return fn(string(key[len(transactionKey):]), &st)
+	})
+}
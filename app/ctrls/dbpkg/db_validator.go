@@ -0,0 +1,108 @@
+package dbpkg
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+)
+
+var (
+	validatorKey        = []byte("validator:")
+	validatorAddressKey = []byte("validator_address:")
+)
+
+var (
+	ERR_VALIDATOR_DOES_NOT_EXIST = func(pub string) error {
+		return errors.New("The validator " + pub + " does not exist.")
+	}
+	ERR_VALIDATOR_ADDRESS_DOES_NOT_EXIST = func(address []byte) error {
+		return errors.New("The validator with the address " + hex.EncodeToString(address) + " does not exist.")
+	}
+)
+
+// STAKE_PRECISION is the precision of the validator's stake, so the sums of the values do not drift
+const STAKE_PRECISION = 8
+
+// StateValidator is a validator that the inflators have set or the coins have been staked to,
+// the validators with zero voting power have been removed
+type StateValidator struct {
+	PubKey string
+	Power  int64   // the power that the inflators have set
+	Staked float64 `json:",omitempty"` // the value of the coins that back the validator
+}
+
+// VotingPower is the power that the inflators have set, with one more power for every whole value of the staked coins
+func (sv *StateValidator) VotingPower() int64 {
+	return sv.Power + int64(math.Floor(utils.ToFixed(sv.Staked, STAKE_PRECISION)))
+}
+
+// ValidatorKey is the key of the validator in the state's tree
+func ValidatorKey(pub string) []byte {
+	return append(append([]byte{}, validatorKey...), []byte(pub)...)
+}
+
+func prefixValidatorAddress(address []byte) []byte {
+	return []byte(string(validatorAddressKey) + hex.EncodeToString(address))
+}
+
+// SetValidator saves the validator with the index of its address, so the evidence can find it
+func (s *State) SetValidator(sv StateValidator) {
+	b, _ := json.Marshal(sv)
+	s.store.Set(ValidatorKey(sv.PubKey), b)
+	pub, _ := hex.DecodeString(sv.PubKey)
+	s.store.Set(prefixValidatorAddress(utils.ValidatorAddress(pub)), []byte(sv.PubKey))
+}
+
+// GetValidatorByAddress returns the public key hex of the validator with the address
+func (s *State) GetValidatorByAddress(address []byte) (string, error) {
+	has := s.store.Has(prefixValidatorAddress(address))
+	if !has {
+		return "", ERR_VALIDATOR_ADDRESS_DOES_NOT_EXIST(address)
+	}
+	return string(s.store.Get(prefixValidatorAddress(address))), nil
+}
+
+// SetValidatorPower sets the power of the validator that the inflators have set, its stake does not change
+func (s *State) SetValidatorPower(pub string, power int64) {
+	sv, err := s.GetValidator(pub)
+	if err != nil {
+		sv = &StateValidator{PubKey: pub}
+	}
+	sv.Power = power
+	s.SetValidator(*sv)
+}
+
+// addValidatorStake adds the value to the validator's stake, the validator is added when it does not exist
+func (s *State) addValidatorStake(pub string, value float64) {
+	sv, err := s.GetValidator(pub)
+	if err != nil {
+		sv = &StateValidator{PubKey: pub}
+	}
+	sv.Staked = utils.ToFixed(sv.Staked+value, STAKE_PRECISION)
+	s.SetValidator(*sv)
+}
+
+func (s *State) GetValidator(pub string) (*StateValidator, error) {
+	has := s.store.Has(ValidatorKey(pub))
+	if !has {
+		return nil, ERR_VALIDATOR_DOES_NOT_EXIST(pub)
+	}
+	sv := StateValidator{}
+	json.Unmarshal(s.store.Get(ValidatorKey(pub)), &sv)
+	return &sv, nil
+}
+
+// GetValidators returns the validators that the inflators have set, in the order of their public keys
+func (s *State) GetValidators() []StateValidator {
+	svs := []StateValidator{}
+	s.store.IteratePrefix(validatorKey, func(key, value []byte) bool {
+		sv := StateValidator{}
+		json.Unmarshal(value, &sv)
+		svs = append(svs, sv)
+		return true
+	})
+	return svs
+}
@@ -1,39 +1,138 @@
 package dbpkg
 
 import (
-	"encoding/json"
+	"errors"
+	"strconv"
 
+	"github.com/tendermint/iavl"
 	dbm "github.com/tendermint/tmlibs/db"
 )
 
 var (
-	stateKey = []byte("stateKey")
+	ERR_VERSION_DOES_NOT_EXIST = func(version int64) error {
+		return errors.New("The version " + strconv.FormatInt(version, 10) + " of the state does not exist.")
+	}
+	ERR_VERSION_HAS_BEEN_PRUNED = func(version int64) error {
+		return errors.New("The version " + strconv.FormatInt(version, 10) + " of the state has been pruned.")
+	}
+	ERR_VERSION_IS_READ_ONLY = func(version int64) error {
+		return errors.New("The version " + strconv.FormatInt(version, 10) + " of the state can not be changed.")
+	}
 )
 
+const treeCacheSize = 10000
+
+// The state is saved in an IAVL tree, so its root hash is the app hash
+// and every key can be proven to the light clients.
 type State struct {
-	db      dbm.DB
+	tree    *iavl.VersionedTree
+	store   kvStore
 	Size    int64  `json:"size"`
 	Height  int64  `json:"height"`
 	AppHash []byte `json:"app_hash"`
+
+	// KeepVersions is how many of the latest versions are kept, the rest are pruned on commit.
+	// When it is zero, all the versions are kept.
+	KeepVersions int64 `json:"-"`
+
+	// the versions until this one have been pruned, so they are not checked again
+	pruned int64
 }
 
 func LoadState(db dbm.DB) State {
-	stateBytes := db.Get(stateKey)
+	tree := iavl.NewVersionedTree(db, treeCacheSize)
+	_, err := tree.Load()
+	if err != nil {
+		panic(err)
+	}
 	var state State
-	if len(stateBytes) != 0 {
-		err := json.Unmarshal(stateBytes, &state)
+	state.tree = tree
+	state.store = &treeStore{tree: tree}
+	state.Height = tree.Version()
+	state.AppHash = tree.Hash()
+	state.Size = tree.Size()
+	return state
+}
+
+// Commit saves the changes of the deliveries as a new version of the tree
+func (s *State) Commit() []byte {
+	hash, version, err := s.tree.SaveVersion()
+	if err != nil {
+		panic(err)
+	}
+	s.Height = version
+	s.AppHash = hash
+	s.Size = s.tree.Size()
+	s.prune()
+	return hash
+}
+
+// prune deletes all the versions before the latest versions that are kept,
+// so the older versions are deleted too when KeepVersions has become smaller
+func (s *State) prune() {
+	if s.KeepVersions <= 0 {
+		return
+	}
+	cutoff := s.Height - s.KeepVersions
+	for version := s.pruned + 1; version <= cutoff; version++ {
+		if !s.tree.VersionExists(version) {
+			continue
+		}
+		err := s.tree.DeleteVersion(version)
 		if err != nil {
 			panic(err)
 		}
 	}
-	state.db = db
-	return state
+	if cutoff > s.pruned {
+		s.pruned = cutoff
+	}
 }
 
-func SaveState(state State) {
-	stateBytes, err := json.Marshal(state)
-	if err != nil {
-		panic(err)
+// CacheWrap returns a copy of the state that stages its changes,
+// so they can be written to the state all together or be discarded
+func (s *State) CacheWrap() *State {
+	state := *s
+	state.store = newCacheStore(s.store)
+	return &state
+}
+
+// Write writes the staged changes of the cache to the state that it has been wrapped from
+func (s *State) Write() {
+	if cs, ok := s.store.(*cacheStore); ok {
+		cs.write()
+	}
+}
+
+// AtVersion returns the state as it was saved on the version, that can only be read
+func (s *State) AtVersion(version int64) (*State, error) {
+	if version < 1 || version > s.Height {
+		return nil, ERR_VERSION_DOES_NOT_EXIST(version)
+	}
+	if !s.tree.VersionExists(version) {
+		return nil, ERR_VERSION_HAS_BEEN_PRUNED(version)
+	}
+	state := State{}
+	state.tree = s.tree
+	state.store = &versionStore{tree: s.tree, version: version}
+	state.Height = version
+	return &state, nil
+}
+
+// KeyProof proves to the app hash of a version, that the key has the value
+type KeyProof struct {
+	Key   []byte
+	Value []byte
+	Proof *iavl.RangeProof
+}
+
+func (s *State) Prove(version int64, keys [][]byte) ([]KeyProof, error) {
+	kps := []KeyProof{}
+	for _, key := range keys {
+		value, proof, err := s.tree.GetVersionedWithProof(key, version)
+		if err != nil {
+			return nil, err
+		}
+		kps = append(kps, KeyProof{Key: key, Value: value, Proof: proof})
 	}
-	state.db.Set(stateKey, stateBytes)
+	return kps, nil
 }
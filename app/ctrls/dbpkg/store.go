@@ -0,0 +1,193 @@
+package dbpkg
+
+import (
+	"sort"
+
+	"github.com/tendermint/iavl"
+)
+
+// kvStore is where the state reads and writes its keys
+type kvStore interface {
+	Get(key []byte) []byte
+	Has(key []byte) bool
+	Set(key, value []byte)
+	Delete(key []byte)
+	IteratePrefix(prefix []byte, fn func(key, value []byte) bool) // fn returns false to stop
+	IterateRange(start, end []byte, fn func(key, value []byte) bool)
+}
+
+// prefixEnd returns the first key after all the keys with the prefix
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// treeStore is the working version of the tree, that the deliveries change
+type treeStore struct {
+	tree *iavl.VersionedTree
+}
+
+func (ts *treeStore) Get(key []byte) []byte {
+	_, v := ts.tree.Get(key)
+	return v
+}
+
+func (ts *treeStore) Has(key []byte) bool {
+	return ts.tree.Has(key)
+}
+
+func (ts *treeStore) Set(key, value []byte) {
+	ts.tree.Set(key, value)
+}
+
+func (ts *treeStore) Delete(key []byte) {
+	ts.tree.Remove(key)
+}
+
+func (ts *treeStore) IteratePrefix(prefix []byte, fn func(key, value []byte) bool) {
+	ts.IterateRange(prefix, prefixEnd(prefix), fn)
+}
+
+func (ts *treeStore) IterateRange(start, end []byte, fn func(key, value []byte) bool) {
+	ts.tree.IterateRange(start, end, true, func(key, value []byte) bool {
+		return !fn(key, value)
+	})
+}
+
+// versionStore reads a saved version of the tree and it can not be changed
+type versionStore struct {
+	tree    *iavl.VersionedTree
+	version int64
+}
+
+func (vs *versionStore) Get(key []byte) []byte {
+	_, v := vs.tree.GetVersioned(key, vs.version)
+	return v
+}
+
+func (vs *versionStore) Has(key []byte) bool {
+	return vs.Get(key) != nil
+}
+
+func (vs *versionStore) Set(key, value []byte) {
+	panic(ERR_VERSION_IS_READ_ONLY(vs.version))
+}
+
+func (vs *versionStore) Delete(key []byte) {
+	panic(ERR_VERSION_IS_READ_ONLY(vs.version))
+}
+
+func (vs *versionStore) IteratePrefix(prefix []byte, fn func(key, value []byte) bool) {
+	vs.IterateRange(prefix, prefixEnd(prefix), fn)
+}
+
+func (vs *versionStore) IterateRange(start, end []byte, fn func(key, value []byte) bool) {
+	keys, values, _, err := vs.tree.GetVersionedRangeWithProof(start, end, 0, vs.version)
+	if err != nil {
+		return
+	}
+	for i := range keys {
+		if !fn(keys[i], values[i]) {
+			return
+		}
+	}
+}
+
+type cacheValue struct {
+	value   []byte
+	deleted bool
+}
+
+// cacheStore stages the writes over its parent, until they are written to it or discarded
+type cacheStore struct {
+	parent kvStore
+	writes map[string]cacheValue
+}
+
+func newCacheStore(parent kvStore) *cacheStore {
+	return &cacheStore{parent: parent, writes: map[string]cacheValue{}}
+}
+
+func (cs *cacheStore) Get(key []byte) []byte {
+	if cv, ok := cs.writes[string(key)]; ok {
+		if cv.deleted {
+			return nil
+		}
+		return cv.value
+	}
+	return cs.parent.Get(key)
+}
+
+func (cs *cacheStore) Has(key []byte) bool {
+	if cv, ok := cs.writes[string(key)]; ok {
+		return !cv.deleted
+	}
+	return cs.parent.Has(key)
+}
+
+func (cs *cacheStore) Set(key, value []byte) {
+	cs.writes[string(key)] = cacheValue{value: value}
+}
+
+func (cs *cacheStore) Delete(key []byte) {
+	cs.writes[string(key)] = cacheValue{deleted: true}
+}
+
+func (cs *cacheStore) IteratePrefix(prefix []byte, fn func(key, value []byte) bool) {
+	cs.IterateRange(prefix, prefixEnd(prefix), fn)
+}
+
+// IterateRange merges the staged writes with the keys of the parent
+func (cs *cacheStore) IterateRange(start, end []byte, fn func(key, value []byte) bool) {
+	items := map[string][]byte{}
+	cs.parent.IterateRange(start, end, func(key, value []byte) bool {
+		items[string(key)] = value
+		return true
+	})
+	for k, cv := range cs.writes {
+		if k < string(start) || (end != nil && k >= string(end)) {
+			continue
+		}
+		if cv.deleted {
+			delete(items, k)
+		} else {
+			items[k] = cv.value
+		}
+	}
+	keys := []string{}
+	for k := range items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !fn([]byte(k), items[k]) {
+			return
+		}
+	}
+}
+
+// write applies the staged writes to the parent in the order of the keys,
+// because the order changes the shape of the tree and so the app hash
+func (cs *cacheStore) write() {
+	keys := []string{}
+	for k := range cs.writes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cv := cs.writes[k]
+		if cv.deleted {
+			cs.parent.Delete([]byte(k))
+		} else {
+			cs.parent.Set([]byte(k), cv.value)
+		}
+	}
+	cs.writes = map[string]cacheValue{}
+}
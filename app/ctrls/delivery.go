@@ -3,6 +3,7 @@ package ctrls
 import (
 	"encoding/hex"
 	"encoding/json"
+	"sort"
 
 	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
 
@@ -11,20 +12,47 @@ import (
 	"github.com/tendermint/abci/types"
 )
 
+// DeliverTx changes the state only when the delivery is successful,
+// otherwise all of its changes are discarded
 func (app *TMApplication) DeliverTx(tx []byte) types.ResponseDeliverTx {
-	dts := models.Delivery{}
-	err := json.Unmarshal(tx, &dts)
+	cache := app.state.CacheWrap()
+	resp := deliver(cache, tx)
+	if resp.Code != models.CodeTypeOK {
+		return failed(resp)
+	}
+	cache.Write()
+	return resp
+}
+
+// failed adds the codespace and the data of the error to the failed response,
+// so the clients can use the code without parsing the log
+func failed(resp types.ResponseDeliverTx) types.ResponseDeliverTx {
+	resp.Codespace = models.CODESPACE
+	resp.Data, _ = json.Marshal(models.NewErrorData(resp.Code, resp.Log))
+	return resp
+}
+
+func deliver(state *dbpkg.State, tx []byte) types.ResponseDeliverTx {
+	dts, code, err := models.DecodeDelivery(tx)
+	if err != nil {
+		return types.ResponseDeliverTx{Code: code, Log: err.Error()}
+	}
+	code, err = validations.ValidateDeliveryVersion(state, dts.Version)
 	if err != nil {
-		return types.ResponseDeliverTx{Code: models.CodeTypeEncodingError, Log: "The delivery is not correct json: " + err.Error()}
+		return types.ResponseDeliverTx{Code: code, Log: err.Error()}
 	}
 	sigB, err := hex.DecodeString(dts.Signature)
 	if err != nil {
 		return types.ResponseDeliverTx{Code: models.CodeTypeEncodingError, Log: "The signature is not correct hex: " + err.Error()}
 	}
+	msg, err := dts.SignBytes(state.GetChainID())
+	if err != nil {
+		return types.ResponseDeliverTx{Code: models.CodeTypeEncodingError, Log: err.Error()}
+	}
 	switch dts.GetType() {
 	case models.INFLATE:
 		id := dts.GetInflationData()
-		code, err := validations.ValidateInflation(id, sigB)
+		code, err := validations.ValidateInflation(state, id, msg, sigB)
 		if err != nil {
 			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
 		}
@@ -32,131 +60,281 @@ func (app *TMApplication) DeliverTx(tx []byte) types.ResponseDeliverTx {
 		sc.Coin = id.Coin
 		sc.Owner = id.Owner
 		sc.Value = id.Value
-		err = app.state.AddCoin(sc)
+		sc.Asset = id.Asset
+		code, err = addCoin(state, sc)
 		if err != nil {
-			return types.ResponseDeliverTx{Code: models.CodeTypeUnauthorized, Log: err.Error()}
+			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
 		}
+		state.AddCoinHistory(sc.Coin, dbpkg.COIN_INFLATED, "", sc.Owner)
 	case models.SUM:
 		sd := dts.GetSumData()
-		code, err := validations.ValidateSum(&app.state, sd, sigB)
+		code, err := validations.ValidateSum(state, sd, msg, sigB)
 		if err != nil {
 			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
 		}
 		sum := 0.0
+		asset := models.MAIN_ASSET
 		for _, v := range sd.Coins {
-			sc, err := app.state.GetCoin(v)
+			sc, err := state.GetCoin(v)
 			if err != nil {
-				return types.ResponseDeliverTx{Code: models.CodeTypeUnauthorized, Log: err.Error()}
+				return types.ResponseDeliverTx{Code: models.CodeTypeCoinNotFound, Log: err.Error()}
 			}
 			sum += sc.Value
-			app.state.DeleteCoinAndOwner(v)
+			asset = sc.Asset
+			state.DeleteCoinAndOwner(v)
+			state.AddCoinHistory(v, dbpkg.COIN_SUMMED, sc.Owner, "")
 		}
 
 		sc := dbpkg.StateCoin{}
 		sc.Coin = sd.NewCoin
 		sc.Owner = sd.NewOwner
 		sc.Value = sum
-		err = app.state.AddCoin(sc)
+		sc.Asset = asset
+		code, err = addCoin(state, sc)
 		if err != nil {
-			return types.ResponseDeliverTx{Code: models.CodeTypeUnauthorized, Log: err.Error()}
+			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
+		}
+		state.AddCoinHistory(sc.Coin, dbpkg.COIN_CREATED_BY_SUM, "", sc.Owner)
+		err = payDemurrage(state, sd.Coins, sd.Fee)
+		if err != nil {
+			return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 		}
 	case models.DIVIDE:
 		dd := dts.GetDivitionData()
-		code, err := validations.ValidateDivition(&app.state, dd, sigB)
+		code, err := validations.ValidateDivition(state, dd, msg, sigB)
 		if err != nil {
 			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
 		}
-		app.state.DeleteCoinAndOwner(dd.Coin)
-		for k, v := range dd.NewCoins {
+		old, err := state.GetCoin(dd.Coin)
+		if err != nil {
+			return types.ResponseDeliverTx{Code: models.CodeTypeCoinNotFound, Log: err.Error()}
+		}
+		state.DeleteCoinAndOwner(dd.Coin)
+		state.AddCoinHistory(dd.Coin, dbpkg.COIN_DIVIDED, old.Owner, "")
+		newCoins := []string{}
+		for k := range dd.NewCoins {
+			newCoins = append(newCoins, k)
+		}
+		sort.Strings(newCoins)
+		for _, k := range newCoins {
+			v := dd.NewCoins[k]
 			sc := dbpkg.StateCoin{}
 			sc.Coin = k
 			sc.Owner = v.Owner
 			sc.Value = v.Value
-			err = app.state.AddCoin(sc)
+			sc.Asset = old.Asset
+			code, err = addCoin(state, sc)
 			if err != nil {
-				return types.ResponseDeliverTx{Code: models.CodeTypeUnauthorized, Log: err.Error()}
+				return types.ResponseDeliverTx{Code: code, Log: err.Error()}
 			}
+			state.AddCoinHistory(k, dbpkg.COIN_CREATED_BY_DIVISION, "", sc.Owner)
+		}
+		err = payDemurrage(state, []string{dd.Coin}, dd.Fee)
+		if err != nil {
+			return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 		}
 	case models.TAX:
 		td := dts.GetTaxData()
-		code, err := validations.ValidateTax(&app.state, td, sigB)
+		code, err := validations.ValidateTax(state, td, msg, sigB)
 		if err != nil {
 			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
 		}
-		app.state.AddTax(td)
+		state.AddTax(td)
 	case models.SEND:
 		sd := dts.GetSendData()
-		code, err := validations.ValidateSend(&app.state, sd, sigB)
+		code, err := validations.ValidateSend(state, sd, msg, sigB)
 		if err != nil {
 			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
 		}
-		app.state.AddTransaction(sd)
-		allCoins := append(sd.Coins, sd.Fee...)
-		for _, v := range allCoins {
-			app.state.LockCoin(v)
+		state.AddTransaction(sd)
+		// the fee of the send has paid the demurrage of the coins and of the fee
+		for _, v := range append(append([]string{}, sd.Coins...), sd.Fee...) {
+			state.SetDemurragePaid(v)
+		}
+		if sd.IsStealth() {
+			for _, coin := range sd.Coins {
+				sc, err := state.GetCoin(coin)
+				if err != nil {
+					return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
+				}
+				err = state.DeleteOwner(sc.Owner)
+				if err != nil {
+					return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
+				}
+				err = state.SetNewOwner(coin, sd.StealthOwners[coin])
+				if err != nil {
+					return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
+				}
+				state.AddCoinHistory(coin, dbpkg.COIN_SENT_TO_STEALTH, sc.Owner, sd.StealthOwners[coin])
+			}
+			for _, v := range sd.Fee {
+				state.LockCoin(v)
+				state.AddCoinHistory(v, dbpkg.COIN_SENT, coinOwner(state, v), "")
+			}
+		} else {
+			allCoins := append(sd.Coins, sd.Fee...)
+			for _, v := range allCoins {
+				state.LockCoin(v)
+				state.AddCoinHistory(v, dbpkg.COIN_SENT, coinOwner(state, v), "")
+			}
 		}
 	case models.RECEIVE:
 		rd := dts.GetReceiveData()
-		code, err := validations.ValidateReceive(&app.state, rd, sigB)
+		code, err := validations.ValidateReceive(state, rd, msg, sigB)
 		if err != nil {
 			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
 		}
 
-		for coin, newOwner := range rd.NewOwners {
-			err := app.state.UnlockCoin(coin)
+		coins := sortedKeys(rd.NewOwners)
+		for _, coin := range coins {
+			newOwner := rd.NewOwners[coin]
+			err := state.UnlockCoin(coin)
 			if err != nil {
 				return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 			}
-			sc, err := app.state.GetCoin(coin)
+			sc, err := state.GetCoin(coin)
 			if err != nil {
 				return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 			}
-			err = app.state.DeleteOwner(sc.Owner)
+			err = state.DeleteOwner(sc.Owner)
 			if err != nil {
 				return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 			}
-			err = app.state.SetNewOwner(coin, newOwner)
+			err = state.SetNewOwner(coin, newOwner)
 			if err != nil {
 				return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 			}
+			state.AddCoinHistory(coin, dbpkg.COIN_RECEIVED, sc.Owner, newOwner)
 		}
-		err = app.state.CoinsReceivedFromTransaction(rd.TransactionHash)
+		err = state.CoinsReceivedFromTransaction(rd.TransactionHash, coins)
 		if err != nil {
 			return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 		}
 
 	case models.RETRIEVE_FEE:
 		rd := dts.GetRetrieveData()
-		code, err := validations.ValidateRetrieve(&app.state, rd, sigB)
+		code, err := validations.ValidateRetrieve(state, rd, msg, sigB)
 		if err != nil {
 			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
 		}
-		err = app.state.FeeRetrievedFromTransaction(rd.TransactionHash)
+		err = state.FeeRetrievedFromTransaction(rd.TransactionHash)
 		if err != nil {
 			return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 		}
-		for coin, newOwner := range rd.NewOwners {
-			err := app.state.UnlockCoin(coin)
+		for _, coin := range sortedKeys(rd.NewOwners) {
+			newOwner := rd.NewOwners[coin]
+			err := state.UnlockCoin(coin)
 			if err != nil {
 				return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 			}
-			sc, err := app.state.GetCoin(coin)
+			sc, err := state.GetCoin(coin)
 			if err != nil {
 				return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 			}
-			err = app.state.DeleteOwner(sc.Owner)
+			err = state.DeleteOwner(sc.Owner)
 			if err != nil {
 				return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 			}
-			err = app.state.SetNewOwner(coin, newOwner)
+			err = state.SetNewOwner(coin, newOwner)
 			if err != nil {
 				return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
 			}
+			state.AddCoinHistory(coin, dbpkg.COIN_RECEIVED_AS_FEE, sc.Owner, newOwner)
+		}
+	case models.SET_VALIDATOR:
+		vd := dts.GetValidatorData()
+		code, err := validations.ValidateValidator(state, vd, msg, sigB)
+		if err != nil {
+			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
+		}
+		state.SetValidatorPower(vd.PubKey, vd.Power)
+	case models.STAKE:
+		sd := dts.GetStakeData()
+		code, err := validations.ValidateStake(state, sd, msg, sigB)
+		if err != nil {
+			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
+		}
+		for _, coin := range sd.Coins {
+			sc, err := state.GetCoin(coin)
+			if err != nil {
+				return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
+			}
+			state.LockCoin(coin)
+			state.AddStake(dbpkg.StateStake{Coin: coin, Validator: sd.Validator, Value: sc.Value})
+			state.AddCoinHistory(coin, dbpkg.COIN_STAKED, sc.Owner, "")
+		}
+	case models.UNSTAKE:
+		ud := dts.GetUnstakeData()
+		code, err := validations.ValidateUnstake(state, ud, msg, sigB)
+		if err != nil {
+			return types.ResponseDeliverTx{Code: code, Log: err.Error()}
+		}
+		// the coins of the block are unlocked on the EndBlock of the height after the unbonding blocks
+		height := state.Height + 1 + state.GetUnbondingBlocks()
+		for _, coin := range ud.Coins {
+			err := state.StartUnbonding(coin, height)
+			if err != nil {
+				return types.ResponseDeliverTx{Code: models.CodeTypeServerError, Log: err.Error()}
+			}
+			state.AddCoinHistory(coin, dbpkg.COIN_UNSTAKED, coinOwner(state, coin), "")
 		}
 
 	default:
-		return types.ResponseDeliverTx{Code: models.CodeTypeUnauthorized, Log: "This type of action does not exists."}
+		return types.ResponseDeliverTx{Code: models.CodeTypeDeliveryTypeNotExists, Log: "This type of action does not exists."}
 
 	}
 	return types.ResponseDeliverTx{Code: models.CodeTypeOK}
 }
+
+// sortedKeys returns the keys of the map in order,
+// so every node changes the state in the same order and has the same app hash
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// payDemurrage locks the fee that the coins of the sum or the division have paid,
+// in a transaction that the inflators retrieve
+func payDemurrage(state *dbpkg.State, coins, fee []string) error {
+	if len(fee) == 0 {
+		return nil
+	}
+	hash := dbpkg.DemurrageHash(coins, state.Height+1)
+	if _, err := state.GetTransaction(hash); err == nil {
+		return dbpkg.ERR_TRANSACTION_EXISTS_ALREADY(hash)
+	}
+	for _, v := range fee {
+		state.LockCoin(v)
+		state.SetDemurragePaid(v)
+		state.AddCoinHistory(v, dbpkg.COIN_PAID_DEMURRAGE, coinOwner(state, v), "")
+	}
+	state.AddFeeTransaction(hash, fee)
+	return nil
+}
+
+func coinOwner(state *dbpkg.State, uuid string) string {
+	sc, err := state.GetCoin(uuid)
+	if err != nil {
+		return ""
+	}
+	return sc.Owner
+}
+
+// addCoin adds the coin to the state, with the code of the reason that it can not be added
+func addCoin(state *dbpkg.State, sc dbpkg.StateCoin) (uint32, error) {
+	if _, err := state.GetCoin(sc.Coin); err == nil {
+		return models.CodeTypeCoinExists, dbpkg.ERR_COIN_EXISTS_ALREADY(sc.Coin)
+	}
+	if _, err := state.GetOwner(sc.Owner); err == nil {
+		return models.CodeTypeOwnerExists, dbpkg.ERR_OWNER_EXISTS_ALREADY(sc.Owner)
+	}
+	err := state.AddCoin(sc)
+	if err != nil {
+		return models.CodeTypeServerError, err
+	}
+	return models.CodeTypeOK, nil
+}
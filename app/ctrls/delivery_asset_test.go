@@ -0,0 +1,182 @@
+package ctrls
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dedis/kyber"
+	"github.com/dedis/kyber/group/edwards25519"
+	"github.com/dedis/kyber/util/key"
+	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/query"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/abci/types"
+)
+
+func inflateAsset(app *TMApplication, inflatorKp *key.Pair, inflatorPubHex, asset string, value float64) (types.ResponseDeliverTx, string, *key.Pair) {
+	ownerKp, ownerPubHex := utils.CreateKeyPair()
+	d := models.Delivery{}
+	d.Type = models.INFLATE
+	data := models.InflationData{}
+	data.Coin = uuid.NewV4().String()
+	data.Owner = ownerPubHex
+	data.Inflator = inflatorPubHex
+	data.Value = value
+	data.Asset = asset
+	d.Data = data
+	msg, _ := json.Marshal(d.Data)
+
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	onePrivate := suite.Scalar().Add(inflatorKp.Private, ownerKp.Private)
+	d.Signature, _ = utils.Sign(onePrivate, msg)
+	b, _ := json.Marshal(d)
+	return app.DeliverTx(b), data.Coin, ownerKp
+}
+
+func TestDeliveryAssetInflationFailOnAssetDoesNotExist(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	resp, _, _ := inflateAsset(app, inflatorKp, inflatorPubHex, "points", 1)
+	assert.Equal(t, models.CodeTypeAssetNotCorrect, resp.Code)
+	assert.Equal(t, dbpkg.ERR_ASSET_DOES_NOT_EXIST("points"), errors.New(resp.Log))
+}
+
+func TestDeliveryAssetInflationOnlyByTheInflatorsOfTheAsset(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	assetInflatorKp, assetInflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	app.state.SetAsset(models.Asset{Name: "points", Inflators: []string{assetInflatorPubHex}, Denominations: []float64{10, 1}})
+
+	resp, _, _ := inflateAsset(app, inflatorKp, inflatorPubHex, "points", 1)
+	assert.Equal(t, models.CodeTypeInflatorNotInList, resp.Code)
+
+	resp, _, _ = inflateAsset(app, assetInflatorKp, assetInflatorPubHex, models.MAIN_ASSET, 1)
+	assert.Equal(t, models.CodeTypeInflatorNotInList, resp.Code)
+
+	resp, _, _ = inflateAsset(app, assetInflatorKp, assetInflatorPubHex, "points", 0.5)
+	assert.Equal(t, models.CodeTypeValueNotConstant, resp.Code)
+
+	resp, coin, _ := inflateAsset(app, assetInflatorKp, assetInflatorPubHex, "points", 10)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	sc, err := app.state.GetCoin(coin)
+	assert.Nil(t, err)
+	assert.Equal(t, "points", sc.Asset)
+}
+
+func TestDeliveryAssetSumFailOnCoinsOfDifferentAssets(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	app.state.SetAsset(models.Asset{Name: "points", Inflators: []string{inflatorPubHex}})
+
+	resp, coin1, owner1 := inflateAsset(app, inflatorKp, inflatorPubHex, models.MAIN_ASSET, 0.5)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	resp, coin2, owner2 := inflateAsset(app, inflatorKp, inflatorPubHex, "points", 0.5)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+	d := models.Delivery{}
+	d.Type = models.SUM
+	data := models.SumData{}
+	data.Coins = []string{coin1, coin2}
+	data.NewCoin = uuid.NewV4().String()
+	data.NewOwner = newOwnerPubHex
+	d.Data = data
+	msg, _ := json.Marshal(d.Data)
+	d.Signature, _ = utils.MultiSignature(
+		[]kyber.Scalar{newOwnerKp.Private, owner1.Private, owner2.Private},
+		msg,
+	)
+	b, _ := json.Marshal(d)
+	resp = app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeAssetNotCorrect, resp.Code)
+	assert.Equal(t, validations.ERR_COINS_OF_DIFFERENT_ASSETS, errors.New(resp.Log))
+}
+
+func TestDeliveryAssetSumKeepsTheAsset(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	app.state.SetAsset(models.Asset{Name: "points", Inflators: []string{inflatorPubHex}})
+
+	_, coin1, owner1 := inflateAsset(app, inflatorKp, inflatorPubHex, "points", 0.5)
+	_, coin2, owner2 := inflateAsset(app, inflatorKp, inflatorPubHex, "points", 0.5)
+
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+	d := models.Delivery{}
+	d.Type = models.SUM
+	data := models.SumData{}
+	data.Coins = []string{coin1, coin2}
+	data.NewCoin = uuid.NewV4().String()
+	data.NewOwner = newOwnerPubHex
+	d.Data = data
+	msg, _ := json.Marshal(d.Data)
+	d.Signature, _ = utils.MultiSignature(
+		[]kyber.Scalar{newOwnerKp.Private, owner1.Private, owner2.Private},
+		msg,
+	)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	sc, err := app.state.GetCoin(data.NewCoin)
+	assert.Nil(t, err)
+	assert.Equal(t, "points", sc.Asset)
+}
+
+func TestDeliveryAssetTaxDoesNotChangeTheTaxOfTheMainCurrency(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	app.state.SetAsset(models.Asset{Name: "points", Inflators: []string{inflatorPubHex}})
+	createTax(t, app, inflatorKp, inflatorPubHex, 10)
+
+	d := models.Delivery{}
+	d.Type = models.TAX
+	data := models.TaxData{}
+	data.Percentage = 30
+	data.Inflator = inflatorPubHex
+	data.Asset = "points"
+	msg, _ := json.Marshal(data)
+	d.Signature, _ = utils.Sign(inflatorKp.Private, msg)
+	d.Data = data
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	assert.Equal(t, 10, app.state.GetTax().Percentage)
+	assert.Equal(t, 30, app.state.GetAssetTax("points").Percentage)
+}
+
+func TestQueryListCoinsByValueOfTheAsset(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	app.state.SetAsset(models.Asset{Name: "points", Inflators: []string{inflatorPubHex}})
+
+	resp, mainCoin, _ := inflateAsset(app, inflatorKp, inflatorPubHex, models.MAIN_ASSET, 0.5)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	resp, assetCoin, _ := inflateAsset(app, inflatorKp, inflatorPubHex, "points", 0.5)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	list := func(path string) []string {
+		qresp := app.Query(types.RequestQuery{Path: path})
+		assert.Equal(t, models.CodeTypeOK, qresp.Code)
+		qcl := query.QueryModelCoinList{}
+		json.Unmarshal(qresp.Value, &qcl)
+		coins := []string{}
+		for _, qmc := range qcl.Coins {
+			coins = append(coins, qmc.Coin)
+		}
+		return coins
+	}
+	assert.Equal(t, []string{mainCoin}, list(QUERY_LIST_COINS_BY_VALUE+"?value=0.5"))
+	assert.Equal(t, []string{assetCoin}, list(QUERY_LIST_COINS_BY_VALUE+"?value=0.5&asset=points"))
+}
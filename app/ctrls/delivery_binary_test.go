@@ -0,0 +1,131 @@
+package ctrls
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dedis/kyber"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/abci/types"
+
+	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
+)
+
+func binaryInflation(inflatorPriv kyber.Scalar, inflatorPubHex, chainID string, signJson bool) (models.InflationData, []byte) {
+	ownerKp, ownerPubHex := utils.CreateKeyPair()
+	d := models.Delivery{}
+	d.Type = models.INFLATE
+	data := models.InflationData{}
+	data.Coin = uuid.NewV4().String()
+	data.Owner = ownerPubHex
+	data.Inflator = inflatorPubHex
+	data.Value = 1
+	d.Data = data
+	msg, _ := models.SignBytes(chainID, d.Type, data)
+	if signJson {
+		msg, _ = json.Marshal(data)
+	}
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{inflatorPriv, ownerKp.Private}, msg)
+	b, _ := models.EncodeDelivery(d)
+	return data, b
+}
+
+func TestDeliveryBinaryFailOnVersionNotSupported(t *testing.T) {
+	app := NewTMApplication()
+	resp := app.DeliverTx([]byte{9, 1, 2, 3})
+	assert.Equal(t, models.CodeTypeEncodingError, resp.Code)
+	assert.Equal(t, models.ERR_DELIVERY_VERSION_NOT_SUPPORTED, errors.New(resp.Log))
+}
+
+func TestDeliveryBinaryFailOnJsonSignature(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	_, b := binaryInflation(inflatorKp.Private, inflatorPubHex, "", true)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
+	assert.Equal(t, validations.ERR_SIGNATURE_NOT_VALID, errors.New(resp.Log))
+}
+
+func TestDeliveryBinaryInflationSuccessful(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	app.InitChain(types.RequestInitChain{ChainId: "tendermoney-test"})
+
+	data, b := binaryInflation(inflatorKp.Private, inflatorPubHex, "tendermoney-test", false)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	sc, err := app.state.GetCoin(data.Coin)
+	assert.Nil(t, err)
+	assert.Equal(t, data.Owner, sc.Owner)
+}
+
+func TestDeliveryBinaryFailOnSignatureForAnotherChain(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	app.InitChain(types.RequestInitChain{ChainId: "tendermoney-test"})
+
+	_, b := binaryInflation(inflatorKp.Private, inflatorPubHex, "tendermoney-other", false)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
+	assert.Equal(t, validations.ERR_SIGNATURE_NOT_VALID, errors.New(resp.Log))
+}
+
+func TestDeliveryJsonFailAfterLastJSONHeight(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	app.InitChain(types.RequestInitChain{AppStateBytes: []byte(`{"last_json_height": 1}`)})
+
+	// the first block still accepts the json deliveries
+	newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	app.Commit()
+
+	// the same delivery as json, with the signature of the json
+	_, b := binaryInflation(inflatorKp.Private, inflatorPubHex, "", true)
+	d, _, _ := models.DecodeDelivery(b)
+	jb, _ := json.Marshal(d)
+	resp := app.DeliverTx(jb)
+	assert.Equal(t, models.CodeTypeEncodingError, resp.Code)
+	assert.Equal(t, validations.ERR_JSON_DELIVERIES_NOT_ACCEPTED(1), errors.New(resp.Log))
+
+	// the binary deliveries are accepted
+	_, b = binaryInflation(inflatorKp.Private, inflatorPubHex, "", false)
+	resp = app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+}
+
+func TestDeliveryBinaryDecodesTheMaps(t *testing.T) {
+	dd := models.DivitionData{Coin: uuid.NewV4().String(), NewCoins: map[string]models.Coin{}}
+	for i := 0; i < 5; i++ {
+		_, owner := utils.CreateKeyPair()
+		dd.NewCoins[uuid.NewV4().String()] = models.Coin{Owner: owner, Value: 0.5}
+	}
+	d := models.Delivery{Type: models.DIVIDE, Signature: "ab", Data: dd}
+	b, err := models.EncodeDelivery(d)
+	assert.Nil(t, err)
+
+	decoded, _, err := models.DecodeDelivery(b)
+	assert.Nil(t, err)
+	assert.Equal(t, models.DELIVERY_VERSION_1, decoded.Version)
+	assert.Equal(t, dd, decoded.GetDivitionData())
+
+	// the sign bytes do not depend on the order of the map
+	msg1, _ := models.SignBytes("tendermoney-test", models.DIVIDE, dd)
+	msg2, _ := decoded.SignBytes("tendermoney-test")
+	assert.Equal(t, msg1, msg2)
+
+	// the sign bytes are for the chain
+	msg3, _ := decoded.SignBytes("tendermoney-other")
+	assert.NotEqual(t, msg1, msg3)
+}
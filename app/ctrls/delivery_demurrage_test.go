@@ -0,0 +1,214 @@
+package ctrls
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dedis/kyber"
+	"github.com/dedis/kyber/group/edwards25519"
+	"github.com/dedis/kyber/proof/dleq"
+	"github.com/dedis/kyber/util/key"
+	"github.com/dedis/kyber/util/random"
+	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func createDemurrage(t *testing.T, app *TMApplication, inflatorKp *key.Pair, inflatorPubHex string, demurrage int) {
+	d := models.Delivery{}
+	d.Type = models.TAX
+	data := models.TaxData{}
+	data.Inflator = inflatorPubHex
+	data.Demurrage = demurrage
+	msg, _ := json.Marshal(data)
+	d.Signature, _ = utils.Sign(inflatorKp.Private, msg)
+	d.Data = data
+
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+}
+
+func TestDemurrageFeeOfHolding(t *testing.T) {
+	td := models.TaxData{Demurrage: 10000}
+	assert.Equal(t, 0.04, td.GetDemurrageFee(1, 4, models.CONSTANT_VALUES))
+	assert.Equal(t, 0.0, td.GetDemurrageFee(0.05, 4, models.CONSTANT_VALUES))
+	assert.Equal(t, 5.0, td.GetDemurrageFee(5, 1000, models.CONSTANT_VALUES))
+	assert.Equal(t, 0.0, td.GetDemurrageFee(5, 0, models.CONSTANT_VALUES))
+}
+
+func TestDeliveryTaxFailOnNegativeDemurrage(t *testing.T) {
+	app := NewTMApplication()
+	d := models.Delivery{}
+	d.Type = models.TAX
+	data := models.TaxData{}
+	data.Demurrage = -1
+	d.Data = data
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeTaxNotCorrect, resp.Code)
+	assert.Equal(t, validations.ERR_DEMURRAGE_NEGATIVE, errors.New(resp.Log))
+}
+
+func TestDeliverySumPaysTheDemurrage(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	createDemurrage(t, app, inflatorKp, inflatorPubHex, 10000)
+	coin1, owner1 := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+	coin2, owner2 := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+	feeCoin, feeOwner := newCoin(t, app, inflatorKp, inflatorPubHex, 0.05)
+	for i := 0; i < 4; i++ {
+		app.Commit()
+	}
+
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+	d := models.Delivery{}
+	d.Type = models.SUM
+	data := models.SumData{}
+	data.Coins = []string{coin1, coin2}
+	data.NewCoin = uuid.NewV4().String()
+	data.NewOwner = newOwnerPubHex
+	d.Data = data
+	msg, _ := json.Marshal(d.Data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private, owner1.Private, owner2.Private}, msg)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeFeeInsufficient, resp.Code)
+	assert.Equal(t, validations.ERR_FEE_NOT_BASED_ON_DEMURRAGE(0.04), errors.New(resp.Log))
+
+	data.Fee = []string{feeCoin}
+	d.Data = data
+	msg, _ = json.Marshal(d.Data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private, owner1.Private, owner2.Private, feeOwner.Private}, msg)
+	b, _ = json.Marshal(d)
+	resp = app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	sc, _ := app.state.GetCoin(data.NewCoin)
+	assert.Equal(t, int64(5), sc.Height)
+	isLocked, _ := app.state.IsCoinLocked(feeCoin)
+	assert.True(t, isLocked)
+	st, err := app.state.GetTransaction(dbpkg.DemurrageHash(data.Coins, sc.Height))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{feeCoin}, st.Fee)
+}
+
+func TestDeliverySendFailOnDemurrageNotPaid(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	createDemurrage(t, app, inflatorKp, inflatorPubHex, 10000)
+	coin, _ := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	for i := 0; i < 4; i++ {
+		app.Commit()
+	}
+
+	d := models.Delivery{}
+	d.Type = models.SEND
+	data := models.SendData{}
+	data.Coins = []string{coin}
+	d.Data = data
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeFeeInsufficient, resp.Code)
+	assert.Equal(t, validations.ERR_FEE_NOT_BASED_ON_DEMURRAGE(0.04), errors.New(resp.Log))
+}
+
+func TestDemurrageIsCountedFromTheChangeOfTheTax(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	coin, _ := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	for i := 0; i < 4; i++ {
+		app.Commit()
+	}
+	createDemurrage(t, app, inflatorKp, inflatorPubHex, 10000)
+	app.Commit()
+
+	sc, _ := app.state.GetCoin(coin)
+	assert.Equal(t, int64(1), sc.Height)
+	assert.Equal(t, int64(5), app.state.GetDemurrageHeight(models.MAIN_ASSET))
+	assert.Equal(t, int64(1), app.state.DemurrageBlocks(sc))
+}
+
+func TestDeliverySumFailOnDemurrageTransactionExistsAlready(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	coin1, owner1 := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+	coin2, owner2 := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+	feeCoin, feeOwner := newCoin(t, app, inflatorKp, inflatorPubHex, 0.05)
+	app.Commit()
+
+	// the fee of another delivery with the same coins in the same block
+	hash := dbpkg.DemurrageHash([]string{coin1, coin2}, app.state.Height+1)
+	app.state.AddFeeTransaction(hash, []string{uuid.NewV4().String()})
+
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+	d := models.Delivery{}
+	d.Type = models.SUM
+	data := models.SumData{}
+	data.Coins = []string{coin1, coin2}
+	data.NewCoin = uuid.NewV4().String()
+	data.NewOwner = newOwnerPubHex
+	data.Fee = []string{feeCoin}
+	d.Data = data
+	msg, _ := json.Marshal(d.Data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private, owner1.Private, owner2.Private, feeOwner.Private}, msg)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeServerError, resp.Code)
+	assert.Equal(t, dbpkg.ERR_TRANSACTION_EXISTS_ALREADY(hash), errors.New(resp.Log))
+	isLocked, _ := app.state.IsCoinLocked(feeCoin)
+	assert.False(t, isLocked)
+}
+
+func TestDeliveryReceiveKeepsTheHeightOfTheSend(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	createDemurrage(t, app, inflatorKp, inflatorPubHex, 10000)
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	feeCoin, feeKp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.05)
+	app.Commit()
+
+	rng := random.New()
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+	proof, xG, xH, _ := dleq.NewDLEQProof(suite, g, h, x)
+	transact(t, app, []string{coin}, []string{feeCoin}, proof, []kyber.Scalar{coinKp.Private, feeKp.Private})
+	// the send has paid the demurrage until its block
+	sc, _ := app.state.GetCoin(coin)
+	assert.Equal(t, int64(2), sc.Height)
+	for i := 0; i < 4; i++ {
+		app.Commit()
+	}
+
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+	d := models.Delivery{}
+	d.Type = models.RECEIVE
+	data := models.ReceiveData{}
+	data.TransactionHash = dbpkg.TransactionHash([]string{coin})
+	data.ProofVerification = models.NewProofVerification(g, h, xG, xH)
+	data.NewOwners = map[string]string{coin: newOwnerPubHex}
+	d.Data = data
+	dataB, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private}, dataB)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	// the new owner pays the demurrage of the blocks that the coin has been locked in the transaction
+	sc, _ = app.state.GetCoin(coin)
+	assert.Equal(t, newOwnerPubHex, sc.Owner)
+	assert.Equal(t, int64(2), sc.Height)
+	assert.Equal(t, int64(4), app.state.DemurrageBlocks(sc))
+}
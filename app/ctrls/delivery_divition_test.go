@@ -26,7 +26,7 @@ func TestDeliveryDivitionFailCoinEmpty(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_EMPTY, errors.New(resp.Log))
 }
 
@@ -39,7 +39,7 @@ func TestDeliveryDivitionFailNewCoinsEmpty(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_NEW_COINS_EMPTY, errors.New(resp.Log))
 }
 
@@ -55,7 +55,7 @@ func TestDeliveryDivitionFailNewCoinsOnEmptyOwner(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_NEW_COIN_OWNER_EMPTY(newCoin), errors.New(resp.Log))
 }
 
@@ -75,7 +75,7 @@ func TestDeliveryDivitionFailNewCoinsNonConstantValues(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeValueNotConstant, resp.Code)
 	assert.Equal(t, validations.ERR_NEW_COIN_NON_CONSTANT_VALUE(newCoin), errors.New(resp.Log))
 }
 
@@ -104,7 +104,7 @@ func TestDeliveryDivitionFailTheSameOwner(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinDuplicate, resp.Code)
 	assert.Equal(t, validations.ERR_NEW_COINS_EQUAL_OWNER, errors.New(resp.Log))
 }
 
@@ -132,7 +132,7 @@ func TestDeliveryDivitionFailCoinDoesNotExist(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinNotFound, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_DOES_NOT_EXISTS, errors.New(resp.Log))
 }
 
@@ -162,7 +162,7 @@ func TestDeliveryDivitionFailTheSumOfNewCoins(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeValueNotConstant, resp.Code)
 	assert.Equal(t, validations.ERR_NEW_COINS_IS_NOT_EQUAL_TO_THE_COIN, errors.New(resp.Log))
 }
 
@@ -192,7 +192,7 @@ func TestDeliveryDivitionFailOnNewCoinExists(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinExists, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_FROM_NEW_COINS_EXISTS_ALREADY(coin), errors.New(resp.Log))
 }
 
@@ -222,7 +222,7 @@ func TestDeliveryDivitionFailOnNewOwnerExists(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeOwnerExists, resp.Code)
 	assert.Equal(t, validations.ERR_OWNER_FROM_NEW_COINS_EXISTS_ALREADY(oldOwnerPubHex), errors.New(resp.Log))
 }
 
@@ -267,7 +267,7 @@ func TestDeliveryDivitionFailOnSignature(t *testing.T) {
 
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
 	assert.Equal(t, validations.ERR_SIGNATURE_NOT_VALID, errors.New(resp.Log))
 }
 
@@ -375,6 +375,6 @@ func TestDeliveryDivitionFailOnLockCoin(t *testing.T) {
 
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinLocked, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_IS_LOCKED(coin), errors.New(resp.Log))
 }
@@ -0,0 +1,129 @@
+package ctrls
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryFailOnUnknownField(t *testing.T) {
+	app := NewTMApplication()
+	b := []byte(`{"Type":"inflate","Signature":"ab","Data":{},"Nonce":1}`)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeEncodingError, resp.Code)
+}
+
+func TestDeliveryFailOnUnknownFieldInData(t *testing.T) {
+	app := NewTMApplication()
+	b := []byte(`{"Type":"inflate","Signature":"ab","Data":{"Coin":"` + uuid.NewV4().String() + `","Amount":5}}`)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeBadData, resp.Code)
+}
+
+func TestDeliveryFailOnDataOfWrongShape(t *testing.T) {
+	app := NewTMApplication()
+	b := []byte(`{"Type":"sum","Signature":"ab","Data":{"Coins":"` + uuid.NewV4().String() + `"}}`)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeBadData, resp.Code)
+}
+
+func TestDeliveryFailOnCoinNotUUID(t *testing.T) {
+	app := NewTMApplication()
+	kp, pubHex := utils.CreateKeyPair()
+	di := models.Delivery{}
+	di.Type = models.INFLATE
+	data := models.InflationData{}
+	data.Coin = "not-a-uuid"
+	data.Owner = pubHex
+	data.Inflator = pubHex
+	di.Data = data
+	msg, _ := json.Marshal(di.Data)
+	di.Signature, _ = utils.Sign(kp.Private, msg)
+	b, _ := json.Marshal(di)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeBadData, resp.Code)
+	assert.Equal(t, validations.ERR_COIN_NOT_UUID(data.Coin), errors.New(resp.Log))
+}
+
+func TestDeliveryFailOnPublicKeyNotCorrect(t *testing.T) {
+	app := NewTMApplication()
+	kp, pubHex := utils.CreateKeyPair()
+	di := models.Delivery{}
+	di.Type = models.INFLATE
+	data := models.InflationData{}
+	data.Coin = uuid.NewV4().String()
+	data.Owner = "abcdef"
+	data.Inflator = pubHex
+	di.Data = data
+	msg, _ := json.Marshal(di.Data)
+	di.Signature, _ = utils.Sign(kp.Private, msg)
+	b, _ := json.Marshal(di)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeBadData, resp.Code)
+	assert.Equal(t, validations.ERR_PUBLIC_KEY_NOT_CORRECT(data.Owner), errors.New(resp.Log))
+}
+
+func TestDeliveryFailOnPublicKeyNotLowercase(t *testing.T) {
+	app := NewTMApplication()
+	kp, pubHex := utils.CreateKeyPair()
+	_, ownerPubHex := utils.CreateKeyPair()
+	di := models.Delivery{}
+	di.Type = models.INFLATE
+	data := models.InflationData{}
+	data.Coin = uuid.NewV4().String()
+	data.Owner = strings.ToUpper(ownerPubHex)
+	data.Inflator = pubHex
+	di.Data = data
+	msg, _ := json.Marshal(di.Data)
+	di.Signature, _ = utils.Sign(kp.Private, msg)
+	b, _ := json.Marshal(di)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeBadData, resp.Code)
+	assert.Equal(t, validations.ERR_PUBLIC_KEY_NOT_CORRECT(data.Owner), errors.New(resp.Log))
+}
+
+func TestDeliveryFailOnTooManyCoins(t *testing.T) {
+	app := NewTMApplication()
+	kp, pubHex := utils.CreateKeyPair()
+	di := models.Delivery{}
+	di.Type = models.SUM
+	data := models.SumData{}
+	for i := 0; i <= validations.MAX_COINS_IN_DELIVERY; i++ {
+		data.Coins = append(data.Coins, uuid.NewV4().String())
+	}
+	data.NewCoin = uuid.NewV4().String()
+	data.NewOwner = pubHex
+	di.Data = data
+	msg, _ := json.Marshal(di.Data)
+	di.Signature, _ = utils.Sign(kp.Private, msg)
+	b, _ := json.Marshal(di)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeBadData, resp.Code)
+	assert.Equal(t, validations.ERR_TOO_MANY_COINS, errors.New(resp.Log))
+}
+
+func TestDeliveryFailureHasErrorData(t *testing.T) {
+	app := NewTMApplication()
+	di := models.Delivery{}
+	di.Type = models.INFLATE
+	di.Data = models.InflationData{}
+	b, _ := json.Marshal(di)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
+	assert.Equal(t, models.CODESPACE, resp.Codespace)
+
+	ed := models.ErrorData{}
+	err := json.Unmarshal(resp.Data, &ed)
+	assert.Nil(t, err)
+	assert.Equal(t, models.NewErrorData(models.CodeTypeFieldEmpty, validations.ERR_COIN_EMPTY.Error()), ed)
+	assert.Equal(t, "field_empty", ed.Name)
+}
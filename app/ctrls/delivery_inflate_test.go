@@ -25,7 +25,7 @@ func TestDeliveryInflationFailOnCoinEmpty(t *testing.T) {
 	di.Data = data
 	b, _ := json.Marshal(di)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_EMPTY, errors.New(resp.Log))
 }
 
@@ -38,7 +38,7 @@ func TestDeliveryInflationFailOnSignatureEmpty(t *testing.T) {
 	di.Data = data
 	b, _ := json.Marshal(di)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_SIGNATURE_EMPTY, errors.New(resp.Log))
 
 }
@@ -57,7 +57,7 @@ func TestDeliveryInflationFailOnOwnerEmpty(t *testing.T) {
 	di.Signature, _ = utils.Sign(kp.Private, msg)
 	b, _ := json.Marshal(di)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_OWNER_EMPTY, errors.New(resp.Log))
 
 }
@@ -75,7 +75,7 @@ func TestDeliveryInflationFailOnInflatorEmpty(t *testing.T) {
 	di.Signature, _ = utils.Sign(kp.Private, msg)
 	b, _ := json.Marshal(di)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_INFLATOR_EMPTY, errors.New(resp.Log))
 }
 
@@ -93,7 +93,7 @@ func TestDeliveryInflationFailTheInflatorIsNotInTheList(t *testing.T) {
 	di.Signature, _ = utils.Sign(kp.Private, msg)
 	b, _ := json.Marshal(di)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeInflatorNotInList, resp.Code)
 	assert.Equal(t, validations.ERR_INFLATOR_NOT_IN_LIST, errors.New(resp.Log))
 }
 
@@ -113,7 +113,7 @@ func TestDeliveryInflationFailOnNotConstantValue(t *testing.T) {
 	di.Signature, _ = utils.Sign(kp.Private, msg)
 	b, _ := json.Marshal(di)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeValueNotConstant, resp.Code)
 	assert.Equal(t, validations.ERR_VALUE_NOT_IN_LIST, errors.New(resp.Log))
 }
 
@@ -138,7 +138,7 @@ func TestDeliveryInflationFailOnSignature(t *testing.T) {
 	di.Signature, _ = utils.Sign(onePrivate, msg)
 	b, _ := json.Marshal(di)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
 	assert.Equal(t, validations.ERR_SIGNATURE_NOT_VALID, errors.New(resp.Log))
 }
 
@@ -199,7 +199,7 @@ func TestDeliveryInflationFailCoinOnAlreadyExists(t *testing.T) {
 	di.Signature, _ = utils.Sign(onePrivate, msg)
 	b, _ = json.Marshal(di)
 	resp = app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinExists, resp.Code)
 	assert.Equal(t, dbpkg.ERR_COIN_EXISTS_ALREADY(data.Coin), errors.New(resp.Log))
 }
 
@@ -236,6 +236,6 @@ func TestDeliveryInflationFailOwnerOnAlreadyExists(t *testing.T) {
 	di.Signature, _ = utils.Sign(onePrivate, msg)
 	b, _ = json.Marshal(di)
 	resp = app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeOwnerExists, resp.Code)
 	assert.Equal(t, dbpkg.ERR_OWNER_EXISTS_ALREADY(data.Owner), errors.New(resp.Log))
 }
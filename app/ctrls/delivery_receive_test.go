@@ -12,6 +12,7 @@ import (
 	"github.com/dedis/kyber/proof/dleq"
 	"github.com/dedis/kyber/util/random"
 	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
 	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
@@ -30,7 +31,7 @@ func TestDeliveryReceiveFailOnEmptyHash(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_TRANSACTION_HASH_EMPTY, errors.New(resp.Log))
 }
 
@@ -45,15 +46,14 @@ func TestDeliveryReceiveFailOnHashDoesNotExists(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeTransactionNotFound, resp.Code)
 	assert.Equal(t, validations.ERR_TRANSACTION_HASH_DOES_NOT_EXIST, errors.New(resp.Log))
 }
 
-func TestDeliveryReceiveFailOnNumberOfOwnersNotEqualToTheNumberOfCoins(t *testing.T) {
+func TestDeliveryReceiveFailOnEmptyNewOwners(t *testing.T) {
 	app := NewTMApplication()
 
 	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
-	_, newOwnerPubHex := utils.CreateKeyPair()
 
 	confs.Conf.Inflators = []string{inflatorPubHex}
 
@@ -81,13 +81,12 @@ func TestDeliveryReceiveFailOnNumberOfOwnersNotEqualToTheNumberOfCoins(t *testin
 	data := models.ReceiveData{}
 	data.TransactionHash = hashHex
 	data.NewOwners = map[string]string{}
-	data.NewOwners[coin1] = newOwnerPubHex
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
-	assert.Equal(t, validations.ERR_NEW_OWNERS_NOT_EQUAL_TO_COINS, errors.New(resp.Log))
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
+	assert.Equal(t, validations.ERR_NEW_OWNERS_EMPTY, errors.New(resp.Log))
 }
 
 func TestDeliveryReceiveFailOnCoinDoesNotExistsInTransaction(t *testing.T) {
@@ -127,7 +126,7 @@ func TestDeliveryReceiveFailOnCoinDoesNotExistsInTransaction(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinNotInTransaction, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_IS_NOT_IN_TRANSACTION(fakeCoin), errors.New(resp.Log))
 }
 
@@ -168,7 +167,7 @@ func TestDeliveryReceiveFailOnOwnerExistsAlready(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeOwnerExists, resp.Code)
 	assert.Equal(t, validations.ERR_OWNER_FROM_COINS_EXISTS_ALREADY(coinPubHex, coin), errors.New(resp.Log))
 }
 
@@ -216,7 +215,7 @@ func TestDeliveryReceiveFailOnProofIsNotCorrect(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeProofNotValid, resp.Code)
 	assert.Equal(t, validations.ERR_PROOF_VERIFICATION_IS_NOT_CORRECT, errors.New(resp.Log))
 }
 
@@ -259,7 +258,7 @@ func TestDeliveryReceiveFailOnProofDoesNotVerify(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeProofNotValid, resp.Code)
 	assert.Equal(t, validations.ERR_PROOF_VERIFICATION_IS_NOT_VALID, errors.New(resp.Log))
 }
 
@@ -304,7 +303,7 @@ func TestDeliveryReceiveFailOnFailOnSignatureDoesNotValidate(t *testing.T) {
 	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{inflatorKp.Private}, dataB)
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
 	assert.Equal(t, validations.ERR_SIGNATURE_NOT_VALID, errors.New(resp.Log))
 }
 
@@ -355,7 +354,7 @@ func TestDeliveryReceiveFailOnReceivingTwice(t *testing.T) {
 	assert.Equal(t, models.CodeTypeOK, resps[0].Code)
 
 	// we expect the second time will fail
-	assert.Equal(t, models.CodeTypeUnauthorized, resps[1].Code)
+	assert.Equal(t, models.CodeTypeTransactionReceived, resps[1].Code)
 	assert.Equal(t, validations.ERR_TRANSACTION_HAS_BEEN_RECEIVED, errors.New(resps[1].Log))
 }
 
@@ -425,3 +424,433 @@ func TestDeliveryReceiveSuccess(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, st.IsCoinsReceived)
 }
+
+func TestDeliveryReceiveSuccessWithEncryptedSecret(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	receiverKp, receiverPubHex := utils.CreateKeyPair()
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+
+	rng := random.New()
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	// Create some random secrets and base points
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+	proof, xG, xH, _ := dleq.NewDLEQProof(suite, g, h, x)
+
+	// the sender encrypts the secret for the receiver's public key
+	pvB, _ := json.Marshal(models.NewProofVerification(g, h, xG, xH))
+	encryptedSecret, err := utils.Encrypt(receiverPubHex, pvB)
+	assert.Nil(t, err)
+
+	sendD := models.Delivery{}
+	sendD.Type = models.SEND
+	sendData := models.SendData{}
+	sendData.Coins = []string{coin}
+	sendData.Proof = models.NewProof(proof)
+	sendData.EncryptedSecret = encryptedSecret
+	sendD.Data = sendData
+	sendMsg, _ := json.Marshal(sendData)
+	sendD.Signature, _ = utils.MultiSignature([]kyber.Scalar{coinKp.Private}, sendMsg)
+	sendB, _ := json.Marshal(sendD)
+	resp := app.DeliverTx(sendB)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	msg, _ := json.Marshal(sendData.Coins)
+	hash := sha256.Sum256(msg)
+	hashHex := hex.EncodeToString(hash[:])
+
+	// the receiver finds the secret in the transaction
+	st, err := app.state.GetTransaction(hashHex)
+	assert.Nil(t, err)
+	decryptedB, err := utils.Decrypt(receiverKp.Private, st.EncryptedSecret)
+	assert.Nil(t, err)
+	pv := models.ProofVerification{}
+	err = json.Unmarshal(decryptedB, &pv)
+	assert.Nil(t, err)
+
+	d := models.Delivery{}
+	d.Type = models.RECEIVE
+	data := models.ReceiveData{}
+	data.TransactionHash = hashHex
+	data.ProofVerification = pv
+	data.NewOwners = map[string]string{}
+	data.NewOwners[coin] = newOwnerPubHex
+	d.Data = data
+	dataB, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private}, dataB)
+	b, _ := json.Marshal(d)
+	resp = app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	sc, err := app.state.GetCoin(coin)
+	assert.Nil(t, err)
+	assert.Equal(t, newOwnerPubHex, sc.Owner)
+}
+
+func TestDeliveryReceiveFailOnMissingRecipientSignature(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	_, recipientPubHex := utils.CreateKeyPair()
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+
+	rng := random.New()
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	// Create some random secrets and base points
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+
+	coins := []string{coin}
+	proof, xG, xH, _ := dleq.NewDLEQProof(suite, g, h, x)
+	transactToRecipient(t, app, coins, []string{}, proof, []kyber.Scalar{coinKp.Private}, recipientPubHex)
+
+	msg, _ := json.Marshal(coins)
+	hash := sha256.Sum256(msg)
+	hashHex := hex.EncodeToString(hash[:])
+
+	// someone that has the secret but not the recipient's key
+	d := models.Delivery{}
+	d.Type = models.RECEIVE
+	data := models.ReceiveData{}
+	data.TransactionHash = hashHex
+	data.ProofVerification = models.NewProofVerification(g, h, xG, xH)
+	data.NewOwners = map[string]string{}
+	data.NewOwners[coin] = newOwnerPubHex
+	d.Data = data
+	dataB, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private}, dataB)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
+	assert.Equal(t, validations.ERR_RECIPIENT_SIGNATURE_EMPTY, errors.New(resp.Log))
+}
+
+func TestDeliveryReceiveFailOnRogueKeyForRecipient(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	recipientKp, recipientPubHex := utils.CreateKeyPair()
+	attackerKp, _ := utils.CreateKeyPair()
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin1, coin1Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	coin2, coin2Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+
+	rng := random.New()
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	// Create some random secrets and base points
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+
+	coins := []string{coin1, coin2}
+	proof, xG, xH, _ := dleq.NewDLEQProof(suite, g, h, x)
+	transactToRecipient(t, app, coins, []string{}, proof, []kyber.Scalar{coin1Kp.Private, coin2Kp.Private}, recipientPubHex)
+
+	msg, _ := json.Marshal(coins)
+	hash := sha256.Sum256(msg)
+	hashHex := hex.EncodeToString(hash[:])
+
+	// the rogue key A-R-B cancels out the recipient's key, so the sum of all the keys is the attacker's key
+	rogue := suite.Point().Sub(attackerKp.Public, recipientKp.Public)
+	rogue = suite.Point().Sub(rogue, newOwnerKp.Public)
+	rogueB, _ := rogue.MarshalBinary()
+
+	d := models.Delivery{}
+	d.Type = models.RECEIVE
+	data := models.ReceiveData{}
+	data.TransactionHash = hashHex
+	data.ProofVerification = models.NewProofVerification(g, h, xG, xH)
+	data.NewOwners = map[string]string{}
+	data.NewOwners[coin1] = newOwnerPubHex
+	data.NewOwners[coin2] = hex.EncodeToString(rogueB)
+	d.Data = data
+	dataB, _ := json.Marshal(data)
+	d.Signature, _ = utils.Sign(attackerKp.Private, dataB)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
+	assert.Equal(t, validations.ERR_SIGNATURE_NOT_VALID, errors.New(resp.Log))
+
+	sc, err := app.state.GetCoin(coin1)
+	assert.Nil(t, err)
+	assert.NotEqual(t, newOwnerPubHex, sc.Owner)
+}
+
+func TestDeliveryReceiveFailOnRecipientSignatureFromAnotherKey(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	_, recipientPubHex := utils.CreateKeyPair()
+	anotherKp, _ := utils.CreateKeyPair()
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+
+	rng := random.New()
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	// Create some random secrets and base points
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+
+	coins := []string{coin}
+	proof, xG, xH, _ := dleq.NewDLEQProof(suite, g, h, x)
+	transactToRecipient(t, app, coins, []string{}, proof, []kyber.Scalar{coinKp.Private}, recipientPubHex)
+
+	msg, _ := json.Marshal(coins)
+	hash := sha256.Sum256(msg)
+	hashHex := hex.EncodeToString(hash[:])
+
+	d := models.Delivery{}
+	d.Type = models.RECEIVE
+	data := models.ReceiveData{}
+	data.TransactionHash = hashHex
+	data.ProofVerification = models.NewProofVerification(g, h, xG, xH)
+	data.NewOwners = map[string]string{}
+	data.NewOwners[coin] = newOwnerPubHex
+	dataB, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private}, dataB)
+	data.RecipientSignature, _ = utils.Sign(anotherKp.Private, dataB)
+	d.Data = data
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
+	assert.Equal(t, validations.ERR_RECIPIENT_SIGNATURE_NOT_VALID, errors.New(resp.Log))
+}
+
+func TestDeliveryReceiveSuccessWithRecipient(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	recipientKp, recipientPubHex := utils.CreateKeyPair()
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+
+	rng := random.New()
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	// Create some random secrets and base points
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+
+	coins := []string{coin}
+	proof, xG, xH, _ := dleq.NewDLEQProof(suite, g, h, x)
+	transactToRecipient(t, app, coins, []string{}, proof, []kyber.Scalar{coinKp.Private}, recipientPubHex)
+
+	msg, _ := json.Marshal(coins)
+	hash := sha256.Sum256(msg)
+	hashHex := hex.EncodeToString(hash[:])
+
+	d := models.Delivery{}
+	d.Type = models.RECEIVE
+	data := models.ReceiveData{}
+	data.TransactionHash = hashHex
+	data.ProofVerification = models.NewProofVerification(g, h, xG, xH)
+	data.NewOwners = map[string]string{}
+	data.NewOwners[coin] = newOwnerPubHex
+	dataB, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private}, dataB)
+	data.RecipientSignature, _ = utils.Sign(recipientKp.Private, dataB)
+	d.Data = data
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	sc, err := app.state.GetCoin(coin)
+	assert.Nil(t, err)
+	assert.Equal(t, newOwnerPubHex, sc.Owner)
+}
+
+func TestDeliveryReceivePartialSuccess(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	recipientKp, recipientPubHex := utils.CreateKeyPair()
+
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin1, coin1Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	coin2, coin2Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+
+	rng := random.New()
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	// Create some random secrets and base points
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+
+	coins := []string{coin1, coin2}
+	proof, xG, xH, _ := dleq.NewDLEQProof(suite, g, h, x)
+	transactToRecipient(t, app, coins, []string{}, proof, []kyber.Scalar{coin1Kp.Private, coin2Kp.Private}, recipientPubHex)
+
+	pv := models.NewProofVerification(g, h, xG, xH)
+
+	msg, _ := json.Marshal(coins)
+	hash := sha256.Sum256(msg)
+	hashHex := hex.EncodeToString(hash[:])
+
+	receiveCoin := func(coin string) types.ResponseDeliverTx {
+		newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+		d := models.Delivery{}
+		d.Type = models.RECEIVE
+		data := models.ReceiveData{}
+		data.TransactionHash = hashHex
+		data.ProofVerification = pv
+		data.NewOwners = map[string]string{}
+		data.NewOwners[coin] = newOwnerPubHex
+		dataB, _ := json.Marshal(data)
+		d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private}, dataB)
+		data.RecipientSignature, _ = utils.Sign(recipientKp.Private, dataB)
+		d.Data = data
+		b, _ := json.Marshal(d)
+		return app.DeliverTx(b)
+	}
+
+	// the first coin is received, the second is still locked
+	resp := receiveCoin(coin1)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	st, err := app.state.GetTransaction(hashHex)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{coin1}, st.ReceivedCoins)
+	assert.False(t, st.IsCoinsReceived)
+	isLocked, _ := app.state.IsCoinLocked(coin2)
+	assert.True(t, isLocked)
+
+	// the proof is public after the first receive, but a third party can not replay it for the second coin
+	thirdPartyKp, thirdPartyPubHex := utils.CreateKeyPair()
+	d := models.Delivery{}
+	d.Type = models.RECEIVE
+	data := models.ReceiveData{}
+	data.TransactionHash = hashHex
+	data.ProofVerification = pv
+	data.NewOwners = map[string]string{}
+	data.NewOwners[coin2] = thirdPartyPubHex
+	d.Data = data
+	dataB, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{thirdPartyKp.Private}, dataB)
+	b, _ := json.Marshal(d)
+	resp = app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
+	assert.Equal(t, validations.ERR_RECIPIENT_SIGNATURE_EMPTY, errors.New(resp.Log))
+
+	// the same coin can not be received twice
+	resp = receiveCoin(coin1)
+	assert.Equal(t, models.CodeTypeTransactionReceived, resp.Code)
+	assert.Equal(t, validations.ERR_COIN_HAS_BEEN_RECEIVED(coin1), errors.New(resp.Log))
+
+	// after the second coin, the transaction is received
+	resp = receiveCoin(coin2)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	st, err = app.state.GetTransaction(hashHex)
+	assert.Nil(t, err)
+	assert.True(t, st.IsCoinsReceived)
+}
+
+func TestDeliveryReceiveFailOnPartialWithoutRecipient(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin1, coin1Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	coin2, coin2Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+
+	rng := random.New()
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	// Create some random secrets and base points
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+
+	coins := []string{coin1, coin2}
+	proof, xG, xH, _ := dleq.NewDLEQProof(suite, g, h, x)
+	transact(t, app, coins, []string{}, proof, []kyber.Scalar{coin1Kp.Private, coin2Kp.Private})
+
+	msg, _ := json.Marshal(coins)
+	hash := sha256.Sum256(msg)
+	hashHex := hex.EncodeToString(hash[:])
+
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+	d := models.Delivery{}
+	d.Type = models.RECEIVE
+	data := models.ReceiveData{}
+	data.TransactionHash = hashHex
+	data.ProofVerification = models.NewProofVerification(g, h, xG, xH)
+	data.NewOwners = map[string]string{}
+	data.NewOwners[coin1] = newOwnerPubHex
+	d.Data = data
+	dataB, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private}, dataB)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeCoinNotInTransaction, resp.Code)
+	assert.Equal(t, validations.ERR_PARTIAL_RECEIVE_WITHOUT_RECIPIENT, errors.New(resp.Log))
+
+	isLocked, _ := app.state.IsCoinLocked(coin1)
+	assert.True(t, isLocked)
+}
+
+func TestDeliveryReceiveFailOnStealthTransaction(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	_, _, address := utils.CreateStealthAddress()
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+
+	d := models.Delivery{}
+	d.Type = models.SEND
+	sd := models.SendData{}
+	sd.Coins = []string{coin}
+	stealthR, owners, err := utils.StealthOwners(address, sd.Coins)
+	assert.Nil(t, err)
+	sd.StealthR = stealthR
+	sd.StealthOwners = owners
+	d.Data = sd
+	msg, _ := json.Marshal(sd)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{coinKp.Private}, msg)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+	d = models.Delivery{}
+	d.Type = models.RECEIVE
+	data := models.ReceiveData{}
+	data.TransactionHash = dbpkg.TransactionHash(sd.Coins)
+	data.NewOwners = map[string]string{coin: newOwnerPubHex}
+	d.Data = data
+	dataB, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{newOwnerKp.Private}, dataB)
+	b, _ = json.Marshal(d)
+	resp = app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeTransactionReceived, resp.Code)
+	assert.Equal(t, validations.ERR_STEALTH_TRANSACTION_NOT_RECEIVED, errors.New(resp.Log))
+}
@@ -29,7 +29,7 @@ func TestDeliveryRetrieveFailTransactionDoesNoExist(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeTransactionNotFound, resp.Code)
 	assert.Equal(t, validations.ERR_TRANSACTION_HASH_DOES_NOT_EXIST, errors.New(resp.Log))
 }
 
@@ -66,7 +66,7 @@ func TestDeliveryRetrieveFailTransactionDoesNotHaveFee(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinNotInTransaction, resp.Code)
 	assert.Equal(t, validations.ERR_TRANSACTION_DOES_NOT_HAVE_FEE, errors.New(resp.Log))
 }
 
@@ -105,7 +105,7 @@ func TestDeliveryRetrieveFailNewOwnersEmpty(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinNotInTransaction, resp.Code)
 	assert.Equal(t, validations.ERR_NEW_OWNERS_NOT_EQUAL_TO_FEES, errors.New(resp.Log))
 }
 
@@ -148,7 +148,7 @@ func TestDeliveryRetrieveFailOnInflatorIsEmpty(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_INFLATOR_EMPTY, errors.New(resp.Log))
 }
 
@@ -192,7 +192,7 @@ func TestDeliveryRetrieveFailOnInflatorNotInList(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeInflatorNotInList, resp.Code)
 	assert.Equal(t, validations.ERR_INFLATOR_NOT_IN_LIST, errors.New(resp.Log))
 }
 
@@ -236,7 +236,7 @@ func TestDeliveryRetrieveFailOnNewOwnerExists(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeOwnerExists, resp.Code)
 	assert.Equal(t, validations.ERR_OWNER_FROM_COINS_EXISTS_ALREADY(oldOwnerPubHex, fee), errors.New(resp.Log))
 }
 
@@ -280,7 +280,7 @@ func TestDeliveryRetrieveFailOnCoinIsNotInFee(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinNotInTransaction, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_IS_NOT_IN_FEE_TRANSACTION(coin), errors.New(resp.Log))
 }
 
@@ -326,7 +326,7 @@ func TestDeliveryRetrieveFailOnSignature(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
 	assert.Equal(t, validations.ERR_SIGNATURE_NOT_VALID, errors.New(resp.Log))
 }
 
@@ -377,7 +377,7 @@ func TestDeliveryRetrieveFailOnTransactionHasAlreadyBeenRetrieved(t *testing.T)
 	}
 
 	assert.Equal(t, models.CodeTypeOK, resps[0].Code)
-	assert.Equal(t, models.CodeTypeUnauthorized, resps[1].Code)
+	assert.Equal(t, models.CodeTypeTransactionReceived, resps[1].Code)
 	assert.Equal(t, validations.ERR_TRANSACTION_HAS_BEEN_RETRIEVED, errors.New(resps[1].Log))
 }
 
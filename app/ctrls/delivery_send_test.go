@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/dedis/kyber"
@@ -16,9 +17,11 @@ import (
 	"github.com/mragiadakos/tendermoney/app/confs"
 	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/query"
 	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
 	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
 	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/abci/types"
 )
 
 func TestDeliverySendFailOnEmptyCoins(t *testing.T) {
@@ -31,7 +34,7 @@ func TestDeliverySendFailOnEmptyCoins(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_COINS_EMPTY, errors.New(resp.Log))
 }
 
@@ -52,7 +55,7 @@ func TestDeliverySendFailOnEmptyFees(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFeeInsufficient, resp.Code)
 	assert.Equal(t, validations.ERR_FEES_EMPTY, errors.New(resp.Log))
 }
 
@@ -72,7 +75,7 @@ func TestDeliverySendFailOnCoinAddedTwice(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinDuplicate, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_FROM_COINS_ADDED_TWICE(coin1), errors.New(resp.Log))
 }
 
@@ -94,7 +97,7 @@ func TestDeliverySendFailOnCoinFromFeeAddedTwice(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinDuplicate, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_FROM_FEE_ADDED_TWICE(fee1), errors.New(resp.Log))
 }
 
@@ -116,7 +119,7 @@ func TestDeliverySendFailOnCoinAddedOnBoth(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinDuplicate, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_ADDED_ON_BOTH_COINS_AND_FEE(coin1), errors.New(resp.Log))
 }
 
@@ -132,7 +135,7 @@ func TestDeliverySendFailOnCoinDoesNotExist(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinNotFound, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_FROM_COINS_DOES_NOT_EXISTS(coin), errors.New(resp.Log))
 }
 
@@ -155,7 +158,7 @@ func TestDeliverySendFailOnFeeCoinDoesNotExist(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinNotFound, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_FROM_FEE_DOES_NOT_EXISTS(fee), errors.New(resp.Log))
 }
 
@@ -179,7 +182,7 @@ func TestDeliverySendFailOnFeeNotBasedOnTax(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFeeInsufficient, resp.Code)
 	assert.Equal(t, validations.ERR_FEE_NOT_BASED_ON_TAX(0.02), errors.New(resp.Log))
 }
 
@@ -209,7 +212,7 @@ func TestDeliverySendFailOnSignature(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
 	assert.Equal(t, validations.ERR_SIGNATURE_NOT_VALID, errors.New(resp.Log))
 }
 
@@ -257,7 +260,7 @@ func TestDeliverySendFailOnProofNotEncodedProperly(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeProofNotValid, resp.Code)
 	assert.Equal(t, validations.ERR_PROOF_NOT_CORRECT, errors.New(resp.Log))
 }
 
@@ -357,6 +360,228 @@ func TestDeliverySendFailOnUsingLockedCoin(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinLocked, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_IS_LOCKED(coin), errors.New(resp.Log))
 }
+
+func TestDeliverySendFailOnMemoTooLong(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin1, _ := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+
+	d := models.Delivery{}
+	d.Type = models.SEND
+	data := models.SendData{}
+	data.Coins = []string{coin1}
+	data.Memo = strings.Repeat("a", models.MEMO_MAX_LENGTH+1)
+	d.Data = data
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+
+	assert.Equal(t, models.CodeTypeBadData, resp.Code)
+	assert.Equal(t, validations.ERR_MEMO_TOO_LONG, errors.New(resp.Log))
+}
+
+func TestDeliverySendSuccessfulWithMemo(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	receiverKp, receiverPubHex := utils.CreateKeyPair()
+
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+
+	d := models.Delivery{}
+	d.Type = models.SEND
+	data := models.SendData{}
+	data.Coins = []string{coin}
+	data.Memo = "invoice 42"
+	encryptedMemo, err := utils.Encrypt(receiverPubHex, []byte("order 1234"))
+	assert.Nil(t, err)
+	data.EncryptedMemo = encryptedMemo
+
+	rng := random.New()
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+	proof, _, _, _ := dleq.NewDLEQProof(suite, g, h, x)
+	data.Proof = models.NewProof(proof)
+
+	d.Data = data
+	msg, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{coinKp.Private}, msg)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	coinb, _ := json.Marshal(data.Coins)
+	hash := sha256.Sum256(coinb)
+	hashHex := hex.EncodeToString(hash[:])
+	st, err := app.state.GetTransaction(hashHex)
+	assert.Nil(t, err)
+	assert.Equal(t, "invoice 42", st.Memo)
+
+	// only the receiver can read the encrypted memo
+	memo, err := utils.Decrypt(receiverKp.Private, st.EncryptedMemo)
+	assert.Nil(t, err)
+	assert.Equal(t, "order 1234", string(memo))
+}
+
+func TestDeliverySendFailOnEncryptedSecretNotHex(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin1, _ := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+
+	d := models.Delivery{}
+	d.Type = models.SEND
+	data := models.SendData{}
+	data.Coins = []string{coin1}
+	data.EncryptedSecret = "not hex"
+	d.Data = data
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+
+	assert.Equal(t, models.CodeTypeBadData, resp.Code)
+	assert.Equal(t, validations.ERR_ENCRYPTED_SECRET_NOT_HEX, errors.New(resp.Log))
+}
+
+func TestDeliverySendStealthFailOnMissingOwner(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	_, _, address := utils.CreateStealthAddress()
+
+	coin1, _ := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+	coin2, _ := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+
+	stealthR, owners, err := utils.StealthOwners(address, []string{coin1})
+	assert.Nil(t, err)
+
+	d := models.Delivery{}
+	d.Type = models.SEND
+	data := models.SendData{}
+	data.Coins = []string{coin1, coin2}
+	data.StealthR = stealthR
+	data.StealthOwners = owners
+	d.Data = data
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+
+	assert.Equal(t, models.CodeTypeCoinNotInTransaction, resp.Code)
+	assert.Equal(t, validations.ERR_STEALTH_OWNERS_NOT_EQUAL_TO_COINS, errors.New(resp.Log))
+}
+
+func TestDeliverySendStealthFailOnReceiveFields(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	_, _, address := utils.CreateStealthAddress()
+	_, recipientPubHex := utils.CreateKeyPair()
+
+	coin, _ := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+
+	rng := random.New()
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+	proof, _, _, _ := dleq.NewDLEQProof(suite, g, h, x)
+
+	sendStealth := func(update func(data *models.SendData)) types.ResponseDeliverTx {
+		stealthR, owners, err := utils.StealthOwners(address, []string{coin})
+		assert.Nil(t, err)
+		d := models.Delivery{}
+		d.Type = models.SEND
+		data := models.SendData{}
+		data.Coins = []string{coin}
+		data.StealthR = stealthR
+		data.StealthOwners = owners
+		update(&data)
+		d.Data = data
+		b, _ := json.Marshal(d)
+		return app.DeliverTx(b)
+	}
+
+	updates := []func(data *models.SendData){
+		func(data *models.SendData) { data.Recipient = recipientPubHex },
+		func(data *models.SendData) { data.EncryptedSecret = "abcd" },
+		func(data *models.SendData) { data.Proof = models.NewProof(proof) },
+	}
+	for _, update := range updates {
+		resp := sendStealth(update)
+		assert.Equal(t, models.CodeTypeBadData, resp.Code)
+		assert.Equal(t, validations.ERR_STEALTH_WITH_RECEIVE, errors.New(resp.Log))
+	}
+}
+
+func TestDeliverySendStealthSuccessful(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	viewKp, spendKp, address := utils.CreateStealthAddress()
+
+	createTax(t, app, inflatorKp, inflatorPubHex, 23)
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	fee1, fee1Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.20)
+	fee2, fee2Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.02)
+	fee3, fee3Kp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.01)
+
+	d := models.Delivery{}
+	d.Type = models.SEND
+	data := models.SendData{}
+	data.Coins = []string{coin}
+	data.Fee = []string{fee1, fee2, fee3}
+	stealthR, owners, err := utils.StealthOwners(address, data.Coins)
+	assert.Nil(t, err)
+	data.StealthR = stealthR
+	data.StealthOwners = owners
+	d.Data = data
+	msg, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{coinKp.Private, fee1Kp.Private, fee2Kp.Private, fee3Kp.Private}, msg)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	// the coin belongs to the one-time owner without receiving it
+	sc, err := app.state.GetCoin(coin)
+	assert.Nil(t, err)
+	assert.Equal(t, owners[coin], sc.Owner)
+	assert.False(t, sc.IsLocked)
+
+	// the fee is still locked for the inflator
+	isLocked, _ := app.state.IsCoinLocked(fee1)
+	assert.True(t, isLocked)
+
+	// the receiver finds the coin by scanning the stealth transactions
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_STEALTH_TRANSACTIONS
+	qresp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeOK, qresp.Code)
+	qts := []query.QueryModelTransaction{}
+	json.Unmarshal(qresp.Value, &qts)
+	assert.Equal(t, 1, len(qts))
+	assert.True(t, qts[0].IsCoinsReceived)
+
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	priv, isMine, err := utils.StealthPrivateKey(viewKp.Private, spendKp.Private, qts[0].StealthR, coin, qts[0].StealthOwners[coin])
+	assert.Nil(t, err)
+	assert.True(t, isMine)
+	pubB, _ := suite.Point().Mul(priv, nil).MarshalBinary()
+	assert.Equal(t, sc.Owner, hex.EncodeToString(pubB))
+
+	// someone else's stealth address does not find it
+	otherViewKp, otherSpendKp, _ := utils.CreateStealthAddress()
+	_, isMine, err = utils.StealthPrivateKey(otherViewKp.Private, otherSpendKp.Private, qts[0].StealthR, coin, qts[0].StealthOwners[coin])
+	assert.Nil(t, err)
+	assert.False(t, isMine)
+}
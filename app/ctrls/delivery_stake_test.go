@@ -0,0 +1,170 @@
+package ctrls
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dedis/kyber"
+	"github.com/dedis/kyber/util/key"
+	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/abci/types"
+)
+
+func stakeCoins(app *TMApplication, coins []string, owners []*key.Pair, validator string) types.ResponseDeliverTx {
+	d := models.Delivery{}
+	d.Type = models.STAKE
+	data := models.StakeData{}
+	data.Coins = coins
+	data.Validator = validator
+	d.Data = data
+	msg, _ := json.Marshal(data)
+	privs := []kyber.Scalar{}
+	for _, owner := range owners {
+		privs = append(privs, owner.Private)
+	}
+	d.Signature, _ = utils.MultiSignature(privs, msg)
+	b, _ := json.Marshal(d)
+	return app.DeliverTx(b)
+}
+
+func unstakeCoins(app *TMApplication, coins []string, owners []*key.Pair) types.ResponseDeliverTx {
+	d := models.Delivery{}
+	d.Type = models.UNSTAKE
+	data := models.UnstakeData{}
+	data.Coins = coins
+	d.Data = data
+	msg, _ := json.Marshal(data)
+	privs := []kyber.Scalar{}
+	for _, owner := range owners {
+		privs = append(privs, owner.Private)
+	}
+	d.Signature, _ = utils.MultiSignature(privs, msg)
+	b, _ := json.Marshal(d)
+	return app.DeliverTx(b)
+}
+
+func TestDeliveryStakeFailOnValidatorNotCorrect(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	coin, owner := newCoin(t, app, inflatorKp, inflatorPubHex, 5)
+
+	resp := stakeCoins(app, []string{coin}, []*key.Pair{owner}, "abcd")
+	assert.Equal(t, models.CodeTypeValidatorNotCorrect, resp.Code)
+	assert.Equal(t, validations.ERR_VALIDATOR_PUBLIC_KEY_NOT_CORRECT, errors.New(resp.Log))
+}
+
+func TestDeliveryStakeFailOnCoinLocked(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	coin, owner := newCoin(t, app, inflatorKp, inflatorPubHex, 5)
+
+	resp := stakeCoins(app, []string{coin}, []*key.Pair{owner}, newValidatorPubKey(1))
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	resp = stakeCoins(app, []string{coin}, []*key.Pair{owner}, newValidatorPubKey(2))
+	assert.Equal(t, models.CodeTypeCoinLocked, resp.Code)
+	assert.Equal(t, validations.ERR_COIN_IS_LOCKED(coin), errors.New(resp.Log))
+}
+
+func TestDeliveryStakeFailOnCoinOfAsset(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	app.state.SetAsset(models.Asset{Name: "points", Inflators: []string{inflatorPubHex}})
+	_, coin, owner := inflateAsset(app, inflatorKp, inflatorPubHex, "points", 5)
+
+	resp := stakeCoins(app, []string{coin}, []*key.Pair{owner}, newValidatorPubKey(1))
+	assert.Equal(t, models.CodeTypeAssetNotCorrect, resp.Code)
+	assert.Equal(t, validations.ERR_STAKE_ONLY_MAIN_ASSET(coin), errors.New(resp.Log))
+}
+
+func TestDeliveryUnstakeFailOnCoinNotStaked(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	coin, owner := newCoin(t, app, inflatorKp, inflatorPubHex, 5)
+
+	resp := unstakeCoins(app, []string{coin}, []*key.Pair{owner})
+	assert.Equal(t, models.CodeTypeStakeNotCorrect, resp.Code)
+	assert.Equal(t, dbpkg.ERR_STAKE_DOES_NOT_EXIST(coin), errors.New(resp.Log))
+}
+
+func TestDeliveryStakeBacksTheVotingPowerUntilTheUnbonding(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	// the validator of the genesis keeps the chain running after the unstake
+	genesisPub, _ := hex.DecodeString(newValidatorPubKey(9))
+	app.InitChain(types.RequestInitChain{Validators: []types.Validator{
+		{PubKey: types.PubKey{Type: ED25519_PUBKEY_TYPE, Data: genesisPub}, Power: 10},
+	}})
+	app.state.SetUnbondingBlocks(2)
+	validator := newValidatorPubKey(1)
+	coin1, owner1 := newCoin(t, app, inflatorKp, inflatorPubHex, 5)
+	coin2, owner2 := newCoin(t, app, inflatorKp, inflatorPubHex, 0.5)
+
+	resp := stakeCoins(app, []string{coin1, coin2}, []*key.Pair{owner1, owner2}, validator)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	isLocked, _ := app.state.IsCoinLocked(coin1)
+	assert.True(t, isLocked)
+	eb := app.EndBlock(types.RequestEndBlock{Height: 1})
+	assert.Equal(t, 1, len(eb.ValidatorUpdates))
+	assert.Equal(t, int64(5), eb.ValidatorUpdates[0].Power)
+	app.Commit()
+
+	resp = unstakeCoins(app, []string{coin1, coin2}, []*key.Pair{owner1, owner2})
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	ss, _ := app.state.GetStake(coin1)
+	assert.Equal(t, int64(4), ss.UnbondingHeight)
+	eb = app.EndBlock(types.RequestEndBlock{Height: 2})
+	assert.Equal(t, int64(0), eb.ValidatorUpdates[0].Power)
+	app.Commit()
+
+	// the coins stay locked until the unbonding height
+	app.EndBlock(types.RequestEndBlock{Height: 3})
+	app.Commit()
+	isLocked, _ = app.state.IsCoinLocked(coin1)
+	assert.True(t, isLocked)
+
+	app.EndBlock(types.RequestEndBlock{Height: 4})
+	app.Commit()
+	isLocked, _ = app.state.IsCoinLocked(coin1)
+	assert.False(t, isLocked)
+	isLocked, _ = app.state.IsCoinLocked(coin2)
+	assert.False(t, isLocked)
+	_, err := app.state.GetStake(coin1)
+	assert.NotNil(t, err)
+	chs := app.state.GetCoinHistory(coin1)
+	assert.Equal(t, dbpkg.COIN_UNBONDED, chs[len(chs)-1].Action)
+}
+
+func TestDeliveryUnstakeKeepsTheValidatorsWhenNoVotingPowerRemains(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	genesis := newValidatorPubKey(9)
+	genesisPub, _ := hex.DecodeString(genesis)
+	app.InitChain(types.RequestInitChain{Validators: []types.Validator{
+		{PubKey: types.PubKey{Type: ED25519_PUBKEY_TYPE, Data: genesisPub}, Power: 10},
+	}})
+	validator := newValidatorPubKey(1)
+	coin, owner := newCoin(t, app, inflatorKp, inflatorPubHex, 5)
+
+	resp := stakeCoins(app, []string{coin}, []*key.Pair{owner}, validator)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	resp = setValidator(app, inflatorKp, inflatorPubHex, genesis, 0)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	eb := app.EndBlock(types.RequestEndBlock{Height: 1})
+	assert.Equal(t, 2, len(eb.ValidatorUpdates))
+	app.Commit()
+
+	// the unstake removes the last voting power, so tendermint keeps its validators
+	resp = unstakeCoins(app, []string{coin}, []*key.Pair{owner})
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	eb = app.EndBlock(types.RequestEndBlock{Height: 2})
+	assert.Equal(t, 0, len(eb.ValidatorUpdates))
+}
@@ -28,7 +28,7 @@ func TestDeliverySumFailOnEmptyCoins(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_COINS_EMPTY, errors.New(resp.Log))
 }
 
@@ -42,7 +42,7 @@ func TestDeliverySumFailOnCoinAddedTwice(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinDuplicate, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_FROM_COINS_ADDED_TWICE(coin), errors.New(resp.Log))
 }
 
@@ -55,7 +55,7 @@ func TestDeliverySumFailOnSignatureEmpty(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_SIGNATURE_EMPTY, errors.New(resp.Log))
 }
 
@@ -71,7 +71,7 @@ func TestDeliverySumFailOnEmptyNewCoin(t *testing.T) {
 	d.Signature, _ = utils.Sign(kp.Private, msg)
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_NEW_COIN_EMPTY, errors.New(resp.Log))
 }
 
@@ -88,7 +88,7 @@ func TestDeliverySumFailOnEmptyNewOwner(t *testing.T) {
 	d.Signature, _ = utils.Sign(kp.Private, msg)
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, validations.ERR_NEW_OWNER_EMPTY, errors.New(resp.Log))
 }
 
@@ -97,12 +97,12 @@ func TestDeliveryOnCoinDoesNotExists(t *testing.T) {
 	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
 	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
 
-	coin1, owner1 := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+	_, owner1 := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
 
 	d := models.Delivery{}
 	d.Type = models.SUM
 	data := models.SumData{}
-	data.Coins = []string{coin1 + "k"}
+	data.Coins = []string{uuid.NewV4().String()}
 	data.NewCoin = uuid.NewV4().String()
 	data.NewOwner = newOwnerPubHex
 	d.Data = data
@@ -113,7 +113,7 @@ func TestDeliveryOnCoinDoesNotExists(t *testing.T) {
 	)
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinNotFound, resp.Code)
 	assert.Equal(t, dbpkg.ERR_COIN_DOES_NOT_EXISTS(data.Coins[0]), errors.New(resp.Log))
 }
 
@@ -139,7 +139,7 @@ func TestDeliverySumFailSumOfCoinsNotConstantValue(t *testing.T) {
 	)
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeValueNotConstant, resp.Code)
 	assert.Equal(t, validations.ERR_SUM_OF_COINS_NON_CONSTANT, errors.New(resp.Log))
 }
 
@@ -164,7 +164,7 @@ func TestDeliverySumFailOnNewCoinExistsAlready(t *testing.T) {
 	)
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinExists, resp.Code)
 	assert.Equal(t, validations.ERR_NEW_COIN_EXISTS_ALREADY, errors.New(resp.Log))
 }
 
@@ -189,7 +189,7 @@ func TestDeliverySumFailOnNewOwnerExistsAlready(t *testing.T) {
 	)
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeOwnerExists, resp.Code)
 	assert.Equal(t, validations.ERR_NEW_OWNER_EXISTS_ALREADY, errors.New(resp.Log))
 }
 
@@ -217,7 +217,7 @@ func TestDeliverySumFailOnSignature(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
 	assert.Equal(t, validations.ERR_SIGNATURE_NOT_VALID, errors.New(resp.Log))
 }
 
@@ -309,7 +309,7 @@ func TestDeliverySumFailOnCoinLocked(t *testing.T) {
 	)
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinLocked, resp.Code)
 	assert.Equal(t, validations.ERR_COIN_IS_LOCKED(coin1), errors.New(resp.Log))
 
 }
@@ -22,7 +22,7 @@ func TestDeliveryTaxFailOnNegativePercentage(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeTaxNotCorrect, resp.Code)
 	assert.Equal(t, validations.ERR_TAX_NEGATIVE, errors.New(resp.Log))
 }
 
@@ -35,7 +35,7 @@ func TestDeliveryTaxFailOnOverPercentage(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeTaxNotCorrect, resp.Code)
 	assert.Equal(t, validations.ERR_TAX_OVER_ONE_PERCENT, errors.New(resp.Log))
 }
 
@@ -48,7 +48,7 @@ func TestDeliveryTaxFailOnNotInflator(t *testing.T) {
 	d.Data = data
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeInflatorNotInList, resp.Code)
 	assert.Equal(t, validations.ERR_INFLATOR_NOT_IN_LIST, errors.New(resp.Log))
 }
 
@@ -71,7 +71,7 @@ func TestDeliveryTaxFailOnSignatureValidate(t *testing.T) {
 	b, _ := json.Marshal(d)
 	resp := app.DeliverTx(b)
 
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeSignatureNotValid, resp.Code)
 	assert.Equal(t, validations.ERR_SIGNATURE_NOT_VALID, errors.New(resp.Log))
 }
 
@@ -102,26 +102,37 @@ func TestDeliveryTaxSuccess(t *testing.T) {
 func TestDeliveryTaxFeeBasedOnConstantValue(t *testing.T) {
 	data := models.TaxData{}
 	data.Percentage = 23
-	fee := data.GetFeeFromTransaction(5)
+	fee := data.GetFeeFromTransaction(5, models.CONSTANT_VALUES)
 	assert.Equal(t, 1.15, fee)
 
-	fee = data.GetFeeFromTransaction(1)
+	fee = data.GetFeeFromTransaction(1, models.CONSTANT_VALUES)
 	assert.Equal(t, 0.23, fee)
 
-	fee = data.GetFeeFromTransaction(0.50)
+	fee = data.GetFeeFromTransaction(0.50, models.CONSTANT_VALUES)
 	assert.Equal(t, 0.12, fee)
 
-	fee = data.GetFeeFromTransaction(0.10)
+	fee = data.GetFeeFromTransaction(0.10, models.CONSTANT_VALUES)
 	assert.Equal(t, 0.02, fee)
 
-	fee = data.GetFeeFromTransaction(0.02)
+	fee = data.GetFeeFromTransaction(0.02, models.CONSTANT_VALUES)
 	assert.Equal(t, 0.01, fee)
 
-	fee = data.GetFeeFromTransaction(0.01)
+	fee = data.GetFeeFromTransaction(0.01, models.CONSTANT_VALUES)
 	assert.Equal(t, 0.01, fee)
 
 	data = models.TaxData{}
 	data.Percentage = 0
-	fee = data.GetFeeFromTransaction(0.01)
+	fee = data.GetFeeFromTransaction(0.01, models.CONSTANT_VALUES)
 	assert.Equal(t, 0.0, fee)
 }
+
+func TestDeliveryTaxFeeBasedOnTheDenominations(t *testing.T) {
+	denominations := []float64{100, 5, 1}
+	data := models.TaxData{}
+	data.Percentage = 23
+	fee := data.GetFeeFromTransaction(10, denominations)
+	assert.Equal(t, 2.0, fee)
+
+	fee = data.GetFeeFromTransaction(1, denominations)
+	assert.Equal(t, 1.0, fee)
+}
@@ -0,0 +1,158 @@
+package ctrls
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dedis/kyber/util/key"
+	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/query"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/abci/types"
+)
+
+func setValidator(app *TMApplication, inflatorKp *key.Pair, inflatorPubHex, pub string, power int64) types.ResponseDeliverTx {
+	d := models.Delivery{}
+	d.Type = models.SET_VALIDATOR
+	data := models.ValidatorData{}
+	data.PubKey = pub
+	data.Power = power
+	data.Inflator = inflatorPubHex
+	msg, _ := json.Marshal(data)
+	d.Signature, _ = utils.Sign(inflatorKp.Private, msg)
+	d.Data = data
+	b, _ := json.Marshal(d)
+	return app.DeliverTx(b)
+}
+
+func newValidatorPubKey(b byte) string {
+	pub := make([]byte, validations.ED25519_PUBLIC_KEY_SIZE)
+	for i := range pub {
+		pub[i] = b
+	}
+	return hex.EncodeToString(pub)
+}
+
+func TestDeliveryValidatorFailOnPublicKeyNotCorrect(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	resp := setValidator(app, inflatorKp, inflatorPubHex, "abcd", 10)
+	assert.Equal(t, models.CodeTypeValidatorNotCorrect, resp.Code)
+	assert.Equal(t, validations.ERR_VALIDATOR_PUBLIC_KEY_NOT_CORRECT, errors.New(resp.Log))
+}
+
+func TestDeliveryValidatorFailOnNegativePower(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	resp := setValidator(app, inflatorKp, inflatorPubHex, newValidatorPubKey(1), -1)
+	assert.Equal(t, models.CodeTypeValidatorNotCorrect, resp.Code)
+	assert.Equal(t, validations.ERR_VALIDATOR_POWER_NEGATIVE, errors.New(resp.Log))
+}
+
+func TestDeliveryValidatorFailOnNotInflator(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	_, otherPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{otherPubHex}
+
+	resp := setValidator(app, inflatorKp, inflatorPubHex, newValidatorPubKey(1), 10)
+	assert.Equal(t, models.CodeTypeInflatorNotInList, resp.Code)
+	assert.Equal(t, validations.ERR_INFLATOR_NOT_IN_LIST, errors.New(resp.Log))
+}
+
+func TestDeliveryValidatorFailOnRemovingValidatorNotActive(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	resp := setValidator(app, inflatorKp, inflatorPubHex, newValidatorPubKey(1), 0)
+	assert.Equal(t, models.CodeTypeValidatorNotCorrect, resp.Code)
+	assert.Equal(t, validations.ERR_VALIDATOR_NOT_ACTIVE, errors.New(resp.Log))
+}
+
+func TestDeliveryValidatorFailOnRemovingTheLastValidator(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	genesis := newValidatorPubKey(1)
+	genesisPub, _ := hex.DecodeString(genesis)
+	app.InitChain(types.RequestInitChain{Validators: []types.Validator{
+		{PubKey: types.PubKey{Type: ED25519_PUBKEY_TYPE, Data: genesisPub}, Power: 10},
+	}})
+
+	resp := setValidator(app, inflatorKp, inflatorPubHex, genesis, 0)
+	assert.Equal(t, models.CodeTypeValidatorNotCorrect, resp.Code)
+	assert.Equal(t, validations.ERR_VALIDATORS_EMPTY, errors.New(resp.Log))
+}
+
+func TestDeliveryValidatorRemovesTheValidatorsOfTheGenesis(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	genesis := newValidatorPubKey(1)
+	genesisPub, _ := hex.DecodeString(genesis)
+	app.InitChain(types.RequestInitChain{Validators: []types.Validator{
+		{PubKey: types.PubKey{Type: ED25519_PUBKEY_TYPE, Data: genesisPub}, Power: 10},
+	}})
+
+	// tendermint has the validators of the genesis already
+	eb := app.EndBlock(types.RequestEndBlock{Height: 1})
+	assert.Equal(t, 0, len(eb.ValidatorUpdates))
+	app.Commit()
+
+	resp := setValidator(app, inflatorKp, inflatorPubHex, newValidatorPubKey(2), 5)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	resp = setValidator(app, inflatorKp, inflatorPubHex, genesis, 0)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	eb = app.EndBlock(types.RequestEndBlock{Height: 2})
+	assert.Equal(t, 2, len(eb.ValidatorUpdates))
+	assert.Equal(t, genesisPub, eb.ValidatorUpdates[0].PubKey.Data)
+	assert.Equal(t, int64(0), eb.ValidatorUpdates[0].Power)
+}
+
+func TestDeliveryValidatorUpdatesOnEndBlock(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	pub1 := newValidatorPubKey(1)
+	pub2 := newValidatorPubKey(2)
+
+	resp := setValidator(app, inflatorKp, inflatorPubHex, pub1, 10)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	resp = setValidator(app, inflatorKp, inflatorPubHex, pub2, 5)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	eb := app.EndBlock(types.RequestEndBlock{Height: 1})
+	assert.Equal(t, 2, len(eb.ValidatorUpdates))
+	pub1B, _ := hex.DecodeString(pub1)
+	assert.Equal(t, pub1B, eb.ValidatorUpdates[0].PubKey.Data)
+	assert.Equal(t, ED25519_PUBKEY_TYPE, eb.ValidatorUpdates[0].PubKey.Type)
+	assert.Equal(t, int64(10), eb.ValidatorUpdates[0].Power)
+	app.Commit()
+
+	// the validators that have not changed are not sent again
+	eb = app.EndBlock(types.RequestEndBlock{Height: 2})
+	assert.Equal(t, 0, len(eb.ValidatorUpdates))
+	app.Commit()
+
+	resp = setValidator(app, inflatorKp, inflatorPubHex, pub2, 0)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	eb = app.EndBlock(types.RequestEndBlock{Height: 3})
+	assert.Equal(t, 1, len(eb.ValidatorUpdates))
+	assert.Equal(t, int64(0), eb.ValidatorUpdates[0].Power)
+	app.Commit()
+
+	qresp := app.Query(types.RequestQuery{Path: QUERY_LIST_VALIDATORS})
+	assert.Equal(t, models.CodeTypeOK, qresp.Code)
+	qmvs := []query.QueryModelValidator{}
+	json.Unmarshal(qresp.Value, &qmvs)
+	assert.Equal(t, []query.QueryModelValidator{{PubKey: pub1, Power: 10, VotingPower: 10}}, qmvs)
+}
@@ -0,0 +1,148 @@
+package ctrls
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
+	"github.com/tendermint/abci/types"
+)
+
+// GenesisState is the app_state of the genesis
+type GenesisState struct {
+	Snapshot *dbpkg.Snapshot      `json:"snapshot,omitempty"` // the state that the chain starts from
+	Coins    []models.GenesisCoin `json:"coins,omitempty"`    // the coins that are allocated before the chain starts
+
+	// the values that the coins of the network can have, the CONSTANT_VALUES are used without them
+	Denominations []float64 `json:"denominations,omitempty"`
+
+	Assets []models.Asset `json:"assets,omitempty"` // the currencies of the network beside the main currency
+
+	// how many blocks the unstaked coins stay locked, the DEFAULT_UNBONDING_BLOCKS are used without them
+	UnbondingBlocks int64 `json:"unbonding_blocks,omitempty"`
+
+	// the percentage of the stake that a misbehaving validator loses, the DEFAULT_SLASH_PERCENTAGE is used without it
+	SlashPercentage *int `json:"slash_percentage,omitempty"`
+
+	// the last height that accepts the json deliveries, they are accepted on every height without it
+	LastJSONHeight int64 `json:"last_json_height,omitempty"`
+}
+
+// InitChain loads the app_state and the validators of the genesis. A genesis that can not be loaded stops the node.
+func (app *TMApplication) InitChain(req types.RequestInitChain) types.ResponseInitChain {
+	if len(req.AppStateBytes) > 0 {
+		app.initAppState(req.AppStateBytes)
+	}
+	// after the snapshot, that could be from another chain
+	setChainID(&app.state, req.ChainId)
+	app.initValidators(req.Validators)
+	app.resetCheckState()
+	return types.ResponseInitChain{}
+}
+
+// initAppState loads the app_state of the genesis
+func (app *TMApplication) initAppState(appState []byte) {
+	gs := GenesisState{}
+	err := json.Unmarshal(appState, &gs)
+	if err != nil {
+		panic("The app_state of the genesis is not correct: " + err.Error())
+	}
+	if gs.Snapshot != nil {
+		err = app.state.Import(gs.Snapshot)
+		if err != nil {
+			panic("The snapshot of the genesis can not be imported: " + err.Error())
+		}
+	}
+	// an empty list of denominations is not the same as the genesis without them
+	if gs.Denominations != nil {
+		_, err = validations.ValidateDenominations(gs.Denominations)
+		if err != nil {
+			panic("The denominations of the genesis are not correct: " + err.Error())
+		}
+		ds := append([]float64{}, gs.Denominations...)
+		sort.Sort(sort.Reverse(sort.Float64Slice(ds)))
+		app.state.SetDenominations(ds)
+	}
+	if gs.UnbondingBlocks < 0 {
+		panic("The unbonding blocks of the genesis can not be negative.")
+	}
+	if gs.UnbondingBlocks > 0 {
+		app.state.SetUnbondingBlocks(gs.UnbondingBlocks)
+	}
+	if gs.SlashPercentage != nil {
+		if *gs.SlashPercentage < 0 || *gs.SlashPercentage > 100 {
+			panic("The slash percentage of the genesis must be from 0 to 100.")
+		}
+		app.state.SetSlashPercentage(*gs.SlashPercentage)
+	}
+	if gs.LastJSONHeight < 0 {
+		panic("The last json height of the genesis can not be negative.")
+	}
+	if gs.LastJSONHeight > 0 {
+		app.state.SetLastJSONHeight(gs.LastJSONHeight)
+	}
+	err = addGenesisAssets(&app.state, gs.Assets)
+	if err != nil {
+		panic("The assets of the genesis can not be added: " + err.Error())
+	}
+	err = addGenesisCoins(&app.state, gs.Coins)
+	if err != nil {
+		panic("The coins of the genesis can not be added: " + err.Error())
+	}
+}
+
+// initValidators saves the validators of the genesis, that tendermint has already,
+// so the inflators can re-weight or remove them and the set is never left empty
+func (app *TMApplication) initValidators(vs []types.Validator) {
+	app.validators = map[string]int64{}
+	for _, v := range vs {
+		pub := hex.EncodeToString(v.PubKey.Data)
+		app.state.SetValidatorPower(pub, v.Power)
+		app.validators[pub] = v.Power
+	}
+}
+
+// setChainID saves the chain id of the genesis, that the binary deliveries sign
+func setChainID(state *dbpkg.State, chainID string) {
+	if len(chainID) > 0 {
+		state.SetChainID(chainID)
+	}
+}
+
+// addGenesisAssets adds the assets of the genesis, all of them or none
+func addGenesisAssets(state *dbpkg.State, assets []models.Asset) error {
+	cache := state.CacheWrap()
+	for _, a := range assets {
+		_, err := validations.ValidateAsset(cache, a)
+		if err != nil {
+			return err
+		}
+		a.Denominations = append([]float64{}, a.Denominations...)
+		sort.Sort(sort.Reverse(sort.Float64Slice(a.Denominations)))
+		cache.SetAsset(a)
+	}
+	cache.Write()
+	return nil
+}
+
+// addGenesisCoins adds the coins of the genesis, all of them or none
+func addGenesisCoins(state *dbpkg.State, gcs []models.GenesisCoin) error {
+	cache := state.CacheWrap()
+	for _, gc := range gcs {
+		_, err := validations.ValidateGenesisCoin(cache, gc)
+		if err != nil {
+			return err
+		}
+		sc := dbpkg.StateCoin{Coin: gc.Coin, Owner: gc.Owner, Value: gc.Value, Asset: gc.Asset}
+		err = cache.AddCoin(sc)
+		if err != nil {
+			return err
+		}
+		cache.AddCoinHistory(sc.Coin, dbpkg.COIN_CREATED_IN_GENESIS, "", sc.Owner)
+	}
+	cache.Write()
+	return nil
+}
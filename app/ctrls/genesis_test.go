@@ -0,0 +1,199 @@
+package ctrls
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dedis/kyber"
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/mragiadakos/tendermoney/app/ctrls/validations"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/abci/types"
+)
+
+func TestGenesisImportsTheSnapshot(t *testing.T) {
+	app := NewTMApplication()
+	sc := newStateCoin(5)
+	app.state.AddCoin(sc)
+	app.Commit()
+
+	snap, err := app.state.Export(0)
+	assert.Nil(t, err)
+	assert.Equal(t, app.state.Height, snap.Height)
+	assert.Equal(t, app.state.AppHash, snap.AppHash)
+	assert.Equal(t, 1, len(snap.Coins))
+	assert.Equal(t, 1, len(snap.Owners))
+
+	b, _ := json.Marshal(GenesisState{Snapshot: snap})
+	newApp := NewTMApplication()
+	newApp.InitChain(types.RequestInitChain{AppStateBytes: b})
+	hash := newApp.state.Commit()
+	assert.Equal(t, snap.Hash, hash)
+
+	got, err := newApp.state.GetCoin(sc.Coin)
+	assert.Nil(t, err)
+	assert.Equal(t, sc.Owner, got.Owner)
+}
+
+func TestGenesisFailOnSnapshotHashNotEqual(t *testing.T) {
+	app := NewTMApplication()
+	app.state.AddCoin(newStateCoin(5))
+	app.Commit()
+
+	snap, err := app.state.Export(0)
+	assert.Nil(t, err)
+	snap.Hash = []byte("another hash")
+
+	err = dbpkg.VerifySnapshot(snap, app.state.AppHash)
+	assert.Equal(t, dbpkg.ERR_SNAPSHOT_HASH_NOT_EQUAL, err)
+}
+
+func TestGenesisFailOnSnapshotNotOfTheTrustedAppHash(t *testing.T) {
+	app := NewTMApplication()
+	app.state.AddCoin(newStateCoin(5))
+	app.Commit()
+	trusted := app.state.AppHash
+	app.state.AddCoin(newStateCoin(5))
+	app.Commit()
+
+	snap, err := app.state.Export(0)
+	assert.Nil(t, err)
+	err = dbpkg.VerifySnapshot(snap, trusted)
+	assert.Equal(t, dbpkg.ERR_SNAPSHOT_APP_HASH_NOT_EQUAL, err)
+}
+
+func TestGenesisFailOnTamperedSnapshotWithItsHash(t *testing.T) {
+	app := NewTMApplication()
+	sc := newStateCoin(5)
+	app.state.AddCoin(sc)
+	app.Commit()
+
+	snap, err := app.state.Export(0)
+	assert.Nil(t, err)
+
+	// the hash of the snapshot is computed again after the value has changed
+	snap.Coins[0].Value = []byte(`{}`)
+	snap.Hash = snap.ImportedHash()
+	err = dbpkg.VerifySnapshot(snap, app.state.AppHash)
+	assert.Equal(t, dbpkg.ERR_SNAPSHOT_ITEM_NOT_PROVEN(snap.Coins[0].Key), err)
+
+	// a key that has been removed
+	snap, _ = app.state.Export(0)
+	snap.Owners = nil
+	snap.Hash = snap.ImportedHash()
+	err = dbpkg.VerifySnapshot(snap, app.state.AppHash)
+	assert.Equal(t, dbpkg.ERR_SNAPSHOT_ITEMS_NOT_EQUAL_TO_PROOF, err)
+}
+
+func TestGenesisAddsTheCoins(t *testing.T) {
+	sc1 := newStateCoin(5)
+	sc2 := newStateCoin(0.5)
+	gs := GenesisState{Coins: []models.GenesisCoin{
+		{Coin: sc1.Coin, Value: sc1.Value, Owner: sc1.Owner},
+		{Coin: sc2.Coin, Value: sc2.Value, Owner: sc2.Owner},
+	}}
+	b, _ := json.Marshal(gs)
+	app := NewTMApplication()
+	app.InitChain(types.RequestInitChain{AppStateBytes: b})
+
+	got, err := app.state.GetCoin(sc2.Coin)
+	assert.Nil(t, err)
+	assert.Equal(t, sc2.Value, got.Value)
+	assert.Equal(t, sc2.Owner, got.Owner)
+	chs := app.state.GetCoinHistory(sc1.Coin)
+	assert.Equal(t, dbpkg.COIN_CREATED_IN_GENESIS, chs[0].Action)
+}
+
+func TestGenesisFailOnCoinValueNotConstant(t *testing.T) {
+	app := NewTMApplication()
+	sc1 := newStateCoin(5)
+	sc2 := newStateCoin(3)
+	err := addGenesisCoins(&app.state, []models.GenesisCoin{
+		{Coin: sc1.Coin, Value: sc1.Value, Owner: sc1.Owner},
+		{Coin: sc2.Coin, Value: sc2.Value, Owner: sc2.Owner},
+	})
+	assert.Equal(t, validations.ERR_VALUE_NOT_IN_LIST, err)
+
+	// none of the coins has been added
+	_, err = app.state.GetCoin(sc1.Coin)
+	assert.NotNil(t, err)
+}
+
+func TestGenesisFailOnCoinAddedTwice(t *testing.T) {
+	app := NewTMApplication()
+	sc := newStateCoin(5)
+	gc := models.GenesisCoin{Coin: sc.Coin, Value: sc.Value, Owner: sc.Owner}
+	err := addGenesisCoins(&app.state, []models.GenesisCoin{gc, gc})
+	assert.Equal(t, dbpkg.ERR_COIN_EXISTS_ALREADY(sc.Coin), err)
+}
+
+func TestGenesisDenominationsAreUsedByTheDeliveries(t *testing.T) {
+	gs := GenesisState{Denominations: []float64{3, 1000, 200}}
+	b, _ := json.Marshal(gs)
+	app := NewTMApplication()
+	app.InitChain(types.RequestInitChain{AppStateBytes: b})
+	assert.Equal(t, []float64{1000, 200, 3}, app.state.GetDenominations())
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	newCoin(t, app, inflatorKp, inflatorPubHex, 3)
+
+	ownerKp, ownerPubHex := utils.CreateKeyPair()
+	d := models.Delivery{}
+	d.Type = models.INFLATE
+	data := models.InflationData{}
+	data.Coin = uuid.NewV4().String()
+	data.Owner = ownerPubHex
+	data.Inflator = inflatorPubHex
+	data.Value = 500
+	d.Data = data
+	msg, _ := json.Marshal(d.Data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{inflatorKp.Private, ownerKp.Private}, msg)
+	b, _ = json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeValueNotConstant, resp.Code)
+	assert.Equal(t, validations.ERR_VALUE_NOT_IN_LIST, errors.New(resp.Log))
+}
+
+func TestGenesisDenominationsDoNotChangeTheConstantValues(t *testing.T) {
+	app := NewTMApplication()
+	ds := app.state.GetDenominations()
+	assert.Equal(t, models.CONSTANT_VALUES, ds)
+
+	ds[0] = 1000
+	assert.Equal(t, float64(500), models.CONSTANT_VALUES[0])
+}
+
+func TestGenesisFailOnDenominationAddedTwice(t *testing.T) {
+	_, err := validations.ValidateDenominations([]float64{5, 1, 5})
+	assert.Equal(t, validations.ERR_DENOMINATION_ADDED_TWICE(5), err)
+}
+
+func TestGenesisAddsTheAssetsBeforeTheCoins(t *testing.T) {
+	_, inflatorPubHex := utils.CreateKeyPair()
+	sc := newStateCoin(10)
+	gs := GenesisState{
+		Assets: []models.Asset{{Name: "points", Inflators: []string{inflatorPubHex}, Denominations: []float64{1, 10}}},
+		Coins:  []models.GenesisCoin{{Coin: sc.Coin, Value: sc.Value, Owner: sc.Owner, Asset: "points"}},
+	}
+	b, _ := json.Marshal(gs)
+	app := NewTMApplication()
+	app.InitChain(types.RequestInitChain{AppStateBytes: b})
+
+	a, err := app.state.GetAsset("points")
+	assert.Nil(t, err)
+	assert.Equal(t, []float64{10, 1}, a.Denominations)
+	got, err := app.state.GetCoin(sc.Coin)
+	assert.Nil(t, err)
+	assert.Equal(t, "points", got.Asset)
+}
+
+func TestGenesisFailOnAssetNameNotCorrect(t *testing.T) {
+	_, inflatorPubHex := utils.CreateKeyPair()
+	app := NewTMApplication()
+	err := addGenesisAssets(&app.state, []models.Asset{{Name: "Points!", Inflators: []string{inflatorPubHex}}})
+	assert.Equal(t, validations.ERR_ASSET_NAME_NOT_CORRECT("Points!"), err)
+}
@@ -0,0 +1,423 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+// DeliveryVersion is the first byte of a binary delivery.
+// The json deliveries start with '{' and they have the version zero.
+type DeliveryVersion byte
+
+const (
+	DELIVERY_VERSION_JSON = DeliveryVersion(0)
+	DELIVERY_VERSION_1    = DeliveryVersion(1)
+)
+
+var (
+	ERR_DELIVERY_IS_EMPTY                = errors.New("The delivery is empty.")
+	ERR_DELIVERY_VERSION_NOT_SUPPORTED   = errors.New("The version of the delivery is not supported.")
+	ERR_DELIVERY_TYPE_CAN_NOT_BE_ENCODED = func(t DeliveryType) error {
+		return errors.New("The delivery type " + string(t) + " can not be encoded.")
+	}
+	ERR_DELIVERY_DATA_NOT_CORRECT = func(t DeliveryType, err error) error {
+		return errors.New("The data of the " + string(t) + " delivery is not correct: " + err.Error())
+	}
+)
+
+var cdc = amino.NewCodec()
+
+// the binary envelope of the delivery, the data is the binary of the type's wire struct
+type envelope struct {
+	Type      string
+	Signature string
+	Data      []byte
+}
+
+// the document that is signed, so a signature can not be used for another chain, type or version
+type signDoc struct {
+	ChainID string
+	Version uint32
+	Type    string
+	Data    []byte
+}
+
+// The wire structs are the binary form of the data, with the maps as slices sorted by the key
+type wireInflation struct {
+	Coin     string
+	Value    float64 `amino:"unsafe"`
+	Owner    string
+	Inflator string
+	Asset    string
+}
+
+type wireNewCoin struct {
+	Coin  string
+	Owner string
+	Value float64 `amino:"unsafe"`
+}
+
+type wireDivition struct {
+	Coin     string
+	NewCoins []wireNewCoin
+	Fee      []string
+}
+
+type wireTax struct {
+	Percentage int64
+	Inflator   string
+	Asset      string
+	Demurrage  int64
+}
+
+type wireOwner struct {
+	Coin  string
+	Owner string
+}
+
+type wireSend struct {
+	Coins           []string
+	Fee             []string
+	Proof           Proof
+	Memo            string
+	EncryptedMemo   string
+	EncryptedSecret string
+	Recipient       string
+	StealthR        string
+	StealthOwners   []wireOwner
+}
+
+type wireReceive struct {
+	TransactionHash    string
+	NewOwners          []wireOwner
+	ProofVerification  ProofVerification
+	RecipientSignature string
+}
+
+type wireRetrieve struct {
+	TransactionHash string
+	NewOwners       []wireOwner
+	Inflator        string
+}
+
+func toWireOwners(m map[string]string) []wireOwner {
+	wos := []wireOwner{}
+	for k, v := range m {
+		wos = append(wos, wireOwner{Coin: k, Owner: v})
+	}
+	sort.Slice(wos, func(i, j int) bool { return wos[i].Coin < wos[j].Coin })
+	return wos
+}
+
+func fromWireOwners(wos []wireOwner) map[string]string {
+	if len(wos) == 0 {
+		return nil
+	}
+	m := map[string]string{}
+	for _, wo := range wos {
+		m[wo.Coin] = wo.Owner
+	}
+	return m
+}
+
+// toWire converts the data of the type to its wire struct
+func toWire(t DeliveryType, data interface{}) (interface{}, error) {
+	d := Delivery{Type: t, Data: data}
+	switch t {
+	case INFLATE:
+		id := d.GetInflationData()
+		return wireInflation(id), nil
+	case SUM:
+		return d.GetSumData(), nil
+	case DIVIDE:
+		dd := d.GetDivitionData()
+		wd := wireDivition{Coin: dd.Coin, NewCoins: []wireNewCoin{}, Fee: dd.Fee}
+		for k, v := range dd.NewCoins {
+			wd.NewCoins = append(wd.NewCoins, wireNewCoin{Coin: k, Owner: v.Owner, Value: v.Value})
+		}
+		sort.Slice(wd.NewCoins, func(i, j int) bool { return wd.NewCoins[i].Coin < wd.NewCoins[j].Coin })
+		return wd, nil
+	case TAX:
+		td := d.GetTaxData()
+		return wireTax{Percentage: int64(td.Percentage), Inflator: td.Inflator, Asset: td.Asset, Demurrage: int64(td.Demurrage)}, nil
+	case SEND:
+		sd := d.GetSendData()
+		return wireSend{
+			Coins:           sd.Coins,
+			Fee:             sd.Fee,
+			Proof:           sd.Proof,
+			Memo:            sd.Memo,
+			EncryptedMemo:   sd.EncryptedMemo,
+			EncryptedSecret: sd.EncryptedSecret,
+			Recipient:       sd.Recipient,
+			StealthR:        sd.StealthR,
+			StealthOwners:   toWireOwners(sd.StealthOwners),
+		}, nil
+	case RECEIVE:
+		rd := d.GetReceiveData()
+		return wireReceive{
+			TransactionHash:    rd.TransactionHash,
+			NewOwners:          toWireOwners(rd.NewOwners),
+			ProofVerification:  rd.ProofVerification,
+			RecipientSignature: rd.RecipientSignature,
+		}, nil
+	case RETRIEVE_FEE:
+		rd := d.GetRetrieveData()
+		return wireRetrieve{
+			TransactionHash: rd.TransactionHash,
+			NewOwners:       toWireOwners(rd.NewOwners),
+			Inflator:        rd.Inflator,
+		}, nil
+	case SET_VALIDATOR:
+		return d.GetValidatorData(), nil
+	case STAKE:
+		return d.GetStakeData(), nil
+	case UNSTAKE:
+		return d.GetUnstakeData(), nil
+	}
+	return nil, ERR_DELIVERY_TYPE_CAN_NOT_BE_ENCODED(t)
+}
+
+// fromWire decodes the binary of the type's wire struct to its data
+func fromWire(t DeliveryType, b []byte) (interface{}, error) {
+	switch t {
+	case INFLATE:
+		wi := wireInflation{}
+		err := cdc.UnmarshalBinaryBare(b, &wi)
+		return InflationData(wi), err
+	case SUM:
+		sd := SumData{}
+		err := cdc.UnmarshalBinaryBare(b, &sd)
+		return sd, err
+	case DIVIDE:
+		wd := wireDivition{}
+		err := cdc.UnmarshalBinaryBare(b, &wd)
+		dd := DivitionData{Coin: wd.Coin, Fee: wd.Fee}
+		if len(wd.NewCoins) > 0 {
+			dd.NewCoins = map[string]Coin{}
+		}
+		for _, nc := range wd.NewCoins {
+			dd.NewCoins[nc.Coin] = Coin{Owner: nc.Owner, Value: nc.Value}
+		}
+		return dd, err
+	case TAX:
+		wt := wireTax{}
+		err := cdc.UnmarshalBinaryBare(b, &wt)
+		return TaxData{Percentage: int(wt.Percentage), Inflator: wt.Inflator, Asset: wt.Asset, Demurrage: int(wt.Demurrage)}, err
+	case SEND:
+		ws := wireSend{}
+		err := cdc.UnmarshalBinaryBare(b, &ws)
+		return SendData{
+			Coins:           ws.Coins,
+			Fee:             ws.Fee,
+			Proof:           ws.Proof,
+			Memo:            ws.Memo,
+			EncryptedMemo:   ws.EncryptedMemo,
+			EncryptedSecret: ws.EncryptedSecret,
+			Recipient:       ws.Recipient,
+			StealthR:        ws.StealthR,
+			StealthOwners:   fromWireOwners(ws.StealthOwners),
+		}, err
+	case RECEIVE:
+		wr := wireReceive{}
+		err := cdc.UnmarshalBinaryBare(b, &wr)
+		return ReceiveData{
+			TransactionHash:    wr.TransactionHash,
+			NewOwners:          fromWireOwners(wr.NewOwners),
+			ProofVerification:  wr.ProofVerification,
+			RecipientSignature: wr.RecipientSignature,
+		}, err
+	case RETRIEVE_FEE:
+		wr := wireRetrieve{}
+		err := cdc.UnmarshalBinaryBare(b, &wr)
+		return RetrieveData{
+			TransactionHash: wr.TransactionHash,
+			NewOwners:       fromWireOwners(wr.NewOwners),
+			Inflator:        wr.Inflator,
+		}, err
+	case SET_VALIDATOR:
+		vd := ValidatorData{}
+		err := cdc.UnmarshalBinaryBare(b, &vd)
+		return vd, err
+	case STAKE:
+		sd := StakeData{}
+		err := cdc.UnmarshalBinaryBare(b, &sd)
+		return sd, err
+	case UNSTAKE:
+		ud := UnstakeData{}
+		err := cdc.UnmarshalBinaryBare(b, &ud)
+		return ud, err
+	}
+	return nil, ERR_DELIVERY_TYPE_CAN_NOT_BE_ENCODED(t)
+}
+
+// unsignedData removes from the data the signatures that are part of it
+func unsignedData(t DeliveryType, data interface{}) interface{} {
+	if t == RECEIVE {
+		d := Delivery{Type: t, Data: data}
+		return d.GetReceiveData().Unsigned()
+	}
+	return data
+}
+
+// SignBytes returns the canonical bytes of the data that the owners sign on the chain, for the binary deliveries
+func SignBytes(chainID string, t DeliveryType, data interface{}) ([]byte, error) {
+	w, err := toWire(t, unsignedData(t, data))
+	if err != nil {
+		return nil, err
+	}
+	wb, err := cdc.MarshalBinaryBare(w)
+	if err != nil {
+		return nil, err
+	}
+	return cdc.MarshalBinaryBare(signDoc{ChainID: chainID, Version: uint32(DELIVERY_VERSION_1), Type: string(t), Data: wb})
+}
+
+// EncodeDelivery encodes the delivery with the latest binary version
+func EncodeDelivery(d Delivery) ([]byte, error) {
+	w, err := toWire(d.Type, d.Data)
+	if err != nil {
+		return nil, err
+	}
+	wb, err := cdc.MarshalBinaryBare(w)
+	if err != nil {
+		return nil, err
+	}
+	eb, err := cdc.MarshalBinaryBare(envelope{Type: string(d.Type), Signature: d.Signature, Data: wb})
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{byte(DELIVERY_VERSION_1)}, eb...), nil
+}
+
+// the json delivery before its data is decoded to the struct of its type
+type jsonDelivery struct {
+	Type      DeliveryType
+	Signature string
+	Data      json.RawMessage
+}
+
+// decodeStrictJSON decodes the json and fails on the fields that the struct does not have
+// and on anything after the json
+func decodeStrictJSON(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("there is more data after the json")
+	}
+	return nil
+}
+
+// decodeJSONData decodes the json data strictly, a null data is left empty
+func decodeJSONData(b []byte, v interface{}) error {
+	if len(b) == 0 || string(b) == "null" {
+		return nil
+	}
+	return decodeStrictJSON(b, v)
+}
+
+// fromJSON decodes the json data of the type to the struct of the type.
+// The data of the types that do not exist is left empty, so the delivery can fail with its own error.
+func fromJSON(t DeliveryType, b []byte) (interface{}, error) {
+	switch t {
+	case INFLATE:
+		id := InflationData{}
+		err := decodeJSONData(b, &id)
+		return id, err
+	case SUM:
+		sd := SumData{}
+		err := decodeJSONData(b, &sd)
+		return sd, err
+	case DIVIDE:
+		dd := DivitionData{}
+		err := decodeJSONData(b, &dd)
+		return dd, err
+	case TAX:
+		td := TaxData{}
+		err := decodeJSONData(b, &td)
+		return td, err
+	case SEND:
+		sd := SendData{}
+		err := decodeJSONData(b, &sd)
+		return sd, err
+	case RECEIVE:
+		rd := ReceiveData{}
+		err := decodeJSONData(b, &rd)
+		return rd, err
+	case RETRIEVE_FEE:
+		rd := RetrieveData{}
+		err := decodeJSONData(b, &rd)
+		return rd, err
+	case SET_VALIDATOR:
+		vd := ValidatorData{}
+		err := decodeJSONData(b, &vd)
+		return vd, err
+	case STAKE:
+		sd := StakeData{}
+		err := decodeJSONData(b, &sd)
+		return sd, err
+	case UNSTAKE:
+		ud := UnstakeData{}
+		err := decodeJSONData(b, &ud)
+		return ud, err
+	}
+	return nil, nil
+}
+
+// DecodeDelivery decodes both the binary deliveries and the older json deliveries.
+// The deliveries that can not be decoded fail with CodeTypeEncodingError
+// and the data that does not have the shape of its type fails with CodeTypeBadData.
+func DecodeDelivery(tx []byte) (*Delivery, uint32, error) {
+	if len(tx) == 0 {
+		return nil, CodeTypeEncodingError, ERR_DELIVERY_IS_EMPTY
+	}
+	d := Delivery{}
+	switch {
+	case tx[0] == '{':
+		jd := jsonDelivery{}
+		err := decodeStrictJSON(tx, &jd)
+		if err != nil {
+			return nil, CodeTypeEncodingError, errors.New("The delivery is not correct json: " + err.Error())
+		}
+		d.Type = jd.Type
+		d.Signature = jd.Signature
+		d.Data, err = fromJSON(d.Type, jd.Data)
+		if err != nil {
+			return nil, CodeTypeBadData, ERR_DELIVERY_DATA_NOT_CORRECT(d.Type, err)
+		}
+		d.Version = DELIVERY_VERSION_JSON
+	case DeliveryVersion(tx[0]) == DELIVERY_VERSION_1:
+		e := envelope{}
+		err := cdc.UnmarshalBinaryBare(tx[1:], &e)
+		if err != nil {
+			return nil, CodeTypeEncodingError, errors.New("The delivery is not correct binary: " + err.Error())
+		}
+		d.Type = DeliveryType(e.Type)
+		d.Signature = e.Signature
+		d.Data, err = fromWire(d.Type, e.Data)
+		if err != nil {
+			return nil, CodeTypeBadData, ERR_DELIVERY_DATA_NOT_CORRECT(d.Type, err)
+		}
+		d.Version = DELIVERY_VERSION_1
+	default:
+		return nil, CodeTypeEncodingError, ERR_DELIVERY_VERSION_NOT_SUPPORTED
+	}
+	return &d, CodeTypeOK, nil
+}
+
+// SignBytes returns the bytes that the signature of the delivery is for on the chain.
+// The json deliveries keep signing the json of their data's struct, without the chain.
+func (d *Delivery) SignBytes(chainID string) ([]byte, error) {
+	if d.Version == DELIVERY_VERSION_JSON {
+		return json.Marshal(unsignedData(d.Type, d.typedData()))
+	}
+	return SignBytes(chainID, d.Type, d.Data)
+}
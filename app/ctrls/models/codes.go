@@ -0,0 +1,91 @@
+package models
+
+// CODESPACE is the codespace of the responses, so the clients know that the codes are from the tendermoney
+const CODESPACE = "tendermoney"
+
+// The codes of the responses. The codes can only be added, a code never changes its meaning.
+const (
+	CodeTypeOK            uint32 = 0
+	CodeTypeEncodingError uint32 = 1
+	CodeTypeBadNonce      uint32 = 2
+	CodeTypeUnauthorized  uint32 = 3
+	CodeTypeServerError   uint32 = 4
+	CodeTypeBadData       uint32 = 5 // the data does not have the shape or the format of its type
+
+	CodeTypeFieldEmpty            uint32 = 6  // a required field of the data is empty
+	CodeTypeSignatureNotValid     uint32 = 7  // the signature is not from all the owners
+	CodeTypeInflatorNotInList     uint32 = 8  // the inflator is not one of the inflators
+	CodeTypeValueNotConstant      uint32 = 9  // the value is not one of the constant values
+	CodeTypeCoinNotFound          uint32 = 10 // the coin does not exist
+	CodeTypeCoinExists            uint32 = 11 // the new coin exists already
+	CodeTypeOwnerExists           uint32 = 12 // the new owner exists already
+	CodeTypeCoinLocked            uint32 = 13 // the coin is locked by a transaction
+	CodeTypeCoinDuplicate         uint32 = 14 // the same coin or owner has been added twice
+	CodeTypeFeeInsufficient       uint32 = 15 // the fee is missing or it is not based on the tax
+	CodeTypeProofNotValid         uint32 = 16 // the proof or its verification is not correct
+	CodeTypeTransactionNotFound   uint32 = 17 // the transaction does not exist
+	CodeTypeTransactionReceived   uint32 = 18 // the coins or the fee of the transaction have been received
+	CodeTypeCoinNotInTransaction  uint32 = 19 // the coins do not match the coins of the transaction
+	CodeTypeTaxNotCorrect         uint32 = 20 // the percentage of the tax is not allowed
+	CodeTypeDeliveryTypeNotExists uint32 = 21 // the type of the delivery does not exist
+	CodeTypeAssetNotCorrect       uint32 = 22 // the asset does not exist or the coins are of different assets
+	CodeTypeValidatorNotCorrect   uint32 = 23 // the public key or the power of the validator is not correct
+	CodeTypeStakeNotCorrect       uint32 = 24 // the coin has not been staked or it is unbonding already
+	CodeTypeNotFound              uint32 = 25 // the item of the query does not exist
+	CodeTypeHeightNotAvailable    uint32 = 26 // the height of the query has not been committed or it has been pruned
+	CodeTypeQueryNotExists        uint32 = 27 // the method of the query does not exist or it can not be proven
+)
+
+// CODE_NAMES is the catalogue of the codes, with a name for each code that does not change
+var CODE_NAMES = map[uint32]string{
+	CodeTypeOK:                    "ok",
+	CodeTypeEncodingError:         "encoding_error",
+	CodeTypeBadNonce:              "bad_nonce",
+	CodeTypeUnauthorized:          "unauthorized",
+	CodeTypeServerError:           "server_error",
+	CodeTypeBadData:               "bad_data",
+	CodeTypeFieldEmpty:            "field_empty",
+	CodeTypeSignatureNotValid:     "signature_not_valid",
+	CodeTypeInflatorNotInList:     "inflator_not_in_list",
+	CodeTypeValueNotConstant:      "value_not_constant",
+	CodeTypeCoinNotFound:          "coin_not_found",
+	CodeTypeCoinExists:            "coin_exists",
+	CodeTypeOwnerExists:           "owner_exists",
+	CodeTypeCoinLocked:            "coin_locked",
+	CodeTypeCoinDuplicate:         "coin_duplicate",
+	CodeTypeFeeInsufficient:       "fee_insufficient",
+	CodeTypeProofNotValid:         "proof_not_valid",
+	CodeTypeTransactionNotFound:   "transaction_not_found",
+	CodeTypeTransactionReceived:   "transaction_received",
+	CodeTypeCoinNotInTransaction:  "coin_not_in_transaction",
+	CodeTypeTaxNotCorrect:         "tax_not_correct",
+	CodeTypeDeliveryTypeNotExists: "delivery_type_not_exists",
+	CodeTypeAssetNotCorrect:       "asset_not_correct",
+	CodeTypeValidatorNotCorrect:   "validator_not_correct",
+	CodeTypeStakeNotCorrect:       "stake_not_correct",
+	CodeTypeNotFound:              "not_found",
+	CodeTypeHeightNotAvailable:    "height_not_available",
+	CodeTypeQueryNotExists:        "query_not_exists",
+}
+
+// ErrorData is the data of the failed responses, so the clients do not need to parse the log
+type ErrorData struct {
+	Codespace string
+	Code      uint32
+	Name      string
+	Message   string
+}
+
+// CodeName returns the name of the code from the catalogue
+func CodeName(code uint32) string {
+	name, ok := CODE_NAMES[code]
+	if !ok {
+		return "unknown"
+	}
+	return name
+}
+
+// NewErrorData returns the data of a failed response
+func NewErrorData(code uint32, log string) ErrorData {
+	return ErrorData{Codespace: CODESPACE, Code: code, Name: CodeName(code), Message: log}
+}
@@ -0,0 +1,11 @@
+package models
+
+// MAIN_ASSET is the asset of the main currency, that the coins without an asset have
+const MAIN_ASSET = ""
+
+// Asset is a currency of the network, beside the main currency, like vouchers or loyalty points
+type Asset struct {
+	Name          string
+	Inflators     []string  // the public keys hex that can inflate the coins and set the tax of the asset
+	Denominations []float64 `json:",omitempty"` // the denominations of the network are used without them
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// IsDenomination checks that the value is one of the denominations
+func IsDenomination(denominations []float64, value float64) bool {
+	for _, v := range denominations {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SmallestDenomination returns the smallest of the denominations
+func SmallestDenomination(denominations []float64) float64 {
+	smallest := 0.0
+	for i, v := range denominations {
+		if i == 0 || v < smallest {
+			smallest = v
+		}
+	}
+	return smallest
+}
+
+// DenominationsPrecision returns the decimal places of the smallest denomination,
+// that the values of the network are rounded to
+func DenominationsPrecision(denominations []float64) int {
+	s := strconv.FormatFloat(SmallestDenomination(denominations), 'f', -1, 64)
+	i := strings.Index(s, ".")
+	if i < 0 {
+		return 0
+	}
+	return len(s) - i - 1
+}
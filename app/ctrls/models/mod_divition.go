@@ -8,4 +8,5 @@ type Coin struct {
 type DivitionData struct {
 	Coin     string
 	NewCoins map[string]Coin
+	Fee      []string `json:",omitempty"` // the coins that pay the demurrage of the coin
 }
@@ -0,0 +1,9 @@
+package models
+
+// GenesisCoin is a coin that is allocated in the genesis, before the chain starts
+type GenesisCoin struct {
+	Coin  string //uuid
+	Value float64
+	Owner string //public key hex
+	Asset string `json:",omitempty"`
+}
@@ -5,4 +5,5 @@ type InflationData struct {
 	Value    float64
 	Owner    string //public key hex
 	Inflator string //public key hex
+	Asset    string `json:",omitempty"` // the asset of the coin, empty for the main currency
 }
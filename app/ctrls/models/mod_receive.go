@@ -107,4 +107,13 @@ type ReceiveData struct {
 	TransactionHash   string            // sha256 hex
 	NewOwners         map[string]string // map[uuid]public_key_hex
 	ProofVerification ProofVerification
+	// the recipient signs the same bytes as the new owners, but with its own key
+	// so the key can not be cancelled out of the new owners' signature
+	RecipientSignature string `json:",omitempty"`
+}
+
+// Unsigned returns the data without the recipient's signature, that is what both signatures are for
+func (rd ReceiveData) Unsigned() ReceiveData {
+	rd.RecipientSignature = ""
+	return rd
 }
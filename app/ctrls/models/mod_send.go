@@ -95,8 +95,35 @@ func NewProof(proof *dleq.Proof) Proof {
 	return p
 }
 
+// the memo is limited in bytes, the encrypted memo in bytes of its hex
+const (
+	MEMO_MAX_LENGTH           = 256
+	ENCRYPTED_MEMO_MAX_LENGTH = 2 * (MEMO_MAX_LENGTH + 48) // ecies adds a point and the gcm tag
+
+	ENCRYPTED_SECRET_MAX_LENGTH = 2048
+)
+
 type SendData struct {
-	Coins []string
-	Fee   []string
-	Proof Proof
+	Coins         []string
+	Fee           []string
+	Proof         Proof
+	Memo          string `json:",omitempty"` // a reference for the receiver in plain text
+	EncryptedMemo string `json:",omitempty"` // ecies hex of the memo for the receiver's public key
+
+	// ecies hex of the json of the ProofVerification for the receiver's public key,
+	// so the secret does not need to be passed to the receiver out of band
+	EncryptedSecret string `json:",omitempty"`
+
+	// the public key hex of the only receiver, who needs to sign the receive next to the new owners.
+	// Without it, anyone with the secret can receive the coins.
+	Recipient string `json:",omitempty"`
+
+	// for a stealth address, the coins go directly to one-time owners and they do not need to be received.
+	// The receiver finds them using the public R.
+	StealthR      string            `json:",omitempty"` // public key hex
+	StealthOwners map[string]string `json:",omitempty"` // map[uuid]one-time public_key_hex
+}
+
+func (sd *SendData) IsStealth() bool {
+	return len(sd.StealthR) > 0 || len(sd.StealthOwners) > 0
 }
@@ -0,0 +1,12 @@
+package models
+
+// StakeData locks the coins of the main currency to back the voting power of the validator
+type StakeData struct {
+	Coins     []string
+	Validator string // the ed25519 public key hex of the validator
+}
+
+// UnstakeData starts the unbonding of the staked coins, they are unlocked after the unbonding period
+type UnstakeData struct {
+	Coins []string
+}
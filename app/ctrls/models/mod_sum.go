@@ -4,4 +4,5 @@ type SumData struct {
 	Coins    []string //uuid
 	NewCoin  string   // signature hex
 	NewOwner string   //public key hex
+	Fee      []string `json:",omitempty"` // the coins that pay the demurrage of the coins
 }
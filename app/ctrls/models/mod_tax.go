@@ -2,21 +2,41 @@ package models
 
 import "github.com/mragiadakos/tendermoney/app/ctrls/utils"
 
+// DEMURRAGE_PARTS is the parts of the coin's value that the demurrage is counted in
+const DEMURRAGE_PARTS = 1000000
+
 type TaxData struct {
 	Percentage int
 	Inflator   string
+	Asset      string `json:",omitempty"` // the asset that the tax is for, empty for the main currency
+	Demurrage  int    `json:",omitempty"` // the millionths of the coin's value that holding it costs for every block
 }
 
-func (td *TaxData) GetFeeFromTransaction(tr float64) float64 {
+// GetFeeFromTransaction returns the fee of the transaction, rounded to the precision
+// of the smallest denomination, and at least the smallest denomination
+func (td *TaxData) GetFeeFromTransaction(tr float64, denominations []float64) float64 {
 	if td.Percentage == 0 {
 		return 0
 	}
 	tax := float64(td.Percentage) / 100.0
 	fee := tr * tax
-	fixed := utils.ToFixed(fee, 2)
+	fixed := utils.ToFixed(fee, DenominationsPrecision(denominations))
 	if fixed == 0 {
-		return 0.01
+		return SmallestDenomination(denominations)
 	}
 
 	return fixed
 }
+
+// GetDemurrageFee returns the fee of holding the coin's value for the blocks, rounded to the precision
+// of the smallest denomination, and not more than the value. The short holdings that round to zero are free.
+func (td *TaxData) GetDemurrageFee(value float64, blocks int64, denominations []float64) float64 {
+	if td.Demurrage == 0 || blocks <= 0 {
+		return 0
+	}
+	fee := value * float64(td.Demurrage) / DEMURRAGE_PARTS * float64(blocks)
+	if fee > value {
+		fee = value
+	}
+	return utils.ToFixed(fee, DenominationsPrecision(denominations))
+}
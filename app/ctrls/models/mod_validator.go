@@ -0,0 +1,8 @@
+package models
+
+// ValidatorData adds, re-weights or removes a validator of tendermint, the zero power removes it
+type ValidatorData struct {
+	PubKey   string // the ed25519 public key hex of the validator
+	Power    int64
+	Inflator string
+}
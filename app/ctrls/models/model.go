@@ -4,6 +4,7 @@ import "encoding/json"
 
 type DeliveryType string
 
+// CONSTANT_VALUES are the denominations of the networks that do not have denominations in their genesis
 var CONSTANT_VALUES = []float64{500, 100, 50, 20, 10, 5, 2, 1, 0.50, 0.20, 0.10, 0.05, 0.02, 0.01}
 
 const (
@@ -14,6 +15,10 @@ const (
 	SEND         = DeliveryType("send")
 	RECEIVE      = DeliveryType("receive")
 	RETRIEVE_FEE = DeliveryType("retrieve_fee")
+
+	SET_VALIDATOR = DeliveryType("set_validator")
+	STAKE         = DeliveryType("stake")
+	UNSTAKE       = DeliveryType("unstake")
 )
 
 type TypeDeliveryInterface interface {
@@ -24,6 +29,7 @@ type Delivery struct {
 	Type      DeliveryType
 	Signature string
 	Data      interface{}
+	Version   DeliveryVersion `json:"-"` // the encoding that the delivery has been decoded from
 }
 
 func (d *Delivery) GetType() DeliveryType {
@@ -31,6 +37,9 @@ func (d *Delivery) GetType() DeliveryType {
 }
 
 func (d *Delivery) GetInflationData() InflationData {
+	if i, ok := d.Data.(InflationData); ok {
+		return i
+	}
 	b, _ := json.Marshal(d.Data)
 	i := InflationData{}
 	json.Unmarshal(b, &i)
@@ -38,6 +47,9 @@ func (d *Delivery) GetInflationData() InflationData {
 }
 
 func (d *Delivery) GetSumData() SumData {
+	if i, ok := d.Data.(SumData); ok {
+		return i
+	}
 	b, _ := json.Marshal(d.Data)
 	i := SumData{}
 	json.Unmarshal(b, &i)
@@ -45,6 +57,9 @@ func (d *Delivery) GetSumData() SumData {
 }
 
 func (d *Delivery) GetDivitionData() DivitionData {
+	if i, ok := d.Data.(DivitionData); ok {
+		return i
+	}
 	b, _ := json.Marshal(d.Data)
 	i := DivitionData{}
 	json.Unmarshal(b, &i)
@@ -52,6 +67,9 @@ func (d *Delivery) GetDivitionData() DivitionData {
 }
 
 func (d *Delivery) GetTaxData() TaxData {
+	if i, ok := d.Data.(TaxData); ok {
+		return i
+	}
 	b, _ := json.Marshal(d.Data)
 	i := TaxData{}
 	json.Unmarshal(b, &i)
@@ -59,6 +77,9 @@ func (d *Delivery) GetTaxData() TaxData {
 }
 
 func (d *Delivery) GetSendData() SendData {
+	if i, ok := d.Data.(SendData); ok {
+		return i
+	}
 	b, _ := json.Marshal(d.Data)
 	i := SendData{}
 	json.Unmarshal(b, &i)
@@ -66,6 +87,9 @@ func (d *Delivery) GetSendData() SendData {
 }
 
 func (d *Delivery) GetReceiveData() ReceiveData {
+	if i, ok := d.Data.(ReceiveData); ok {
+		return i
+	}
 	b, _ := json.Marshal(d.Data)
 	i := ReceiveData{}
 	json.Unmarshal(b, &i)
@@ -73,16 +97,68 @@ func (d *Delivery) GetReceiveData() ReceiveData {
 }
 
 func (d *Delivery) GetRetrieveData() RetrieveData {
+	if i, ok := d.Data.(RetrieveData); ok {
+		return i
+	}
 	b, _ := json.Marshal(d.Data)
 	i := RetrieveData{}
 	json.Unmarshal(b, &i)
 	return i
 }
 
-const (
-	CodeTypeOK            uint32 = 0
-	CodeTypeEncodingError uint32 = 1
-	CodeTypeBadNonce      uint32 = 2
-	CodeTypeUnauthorized  uint32 = 3
-	CodeTypeServerError   uint32 = 4
-)
+func (d *Delivery) GetValidatorData() ValidatorData {
+	if i, ok := d.Data.(ValidatorData); ok {
+		return i
+	}
+	b, _ := json.Marshal(d.Data)
+	i := ValidatorData{}
+	json.Unmarshal(b, &i)
+	return i
+}
+
+func (d *Delivery) GetStakeData() StakeData {
+	if i, ok := d.Data.(StakeData); ok {
+		return i
+	}
+	b, _ := json.Marshal(d.Data)
+	i := StakeData{}
+	json.Unmarshal(b, &i)
+	return i
+}
+
+func (d *Delivery) GetUnstakeData() UnstakeData {
+	if i, ok := d.Data.(UnstakeData); ok {
+		return i
+	}
+	b, _ := json.Marshal(d.Data)
+	i := UnstakeData{}
+	json.Unmarshal(b, &i)
+	return i
+}
+
+// typedData returns the data as the struct of its type
+func (d *Delivery) typedData() interface{} {
+	switch d.Type {
+	case INFLATE:
+		return d.GetInflationData()
+	case SUM:
+		return d.GetSumData()
+	case DIVIDE:
+		return d.GetDivitionData()
+	case TAX:
+		return d.GetTaxData()
+	case SEND:
+		return d.GetSendData()
+	case RECEIVE:
+		return d.GetReceiveData()
+	case RETRIEVE_FEE:
+		return d.GetRetrieveData()
+	case SET_VALIDATOR:
+		return d.GetValidatorData()
+	case STAKE:
+		return d.GetStakeData()
+	case UNSTAKE:
+		return d.GetUnstakeData()
+	}
+	return d.Data
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/url"
 
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 	"github.com/mragiadakos/tendermoney/app/ctrls/query"
 	"github.com/tendermint/abci/types"
@@ -12,6 +13,7 @@ import (
 
 var (
 	ERR_THE_QUERY_METHOD_HAS_NOT_BEEN_FOUND = errors.New("The query's method has not been found.")
+	ERR_THE_QUERY_CAN_NOT_BE_PROVEN         = errors.New("The query's method can not be proven.")
 )
 
 const (
@@ -20,53 +22,190 @@ const (
 	QUERY_GET_LATEST_TAX                      = "get_latest_tax"
 	QUERY_GET_TRANSACTION                     = "get_transaction"
 	QUERY_GET_TRANSACTION_WITH_UNRECEIVED_FEE = "get_transactions_with_unreceived_fee"
+	QUERY_GET_STEALTH_TRANSACTIONS            = "get_stealth_transactions"
+	QUERY_LIST_TRANSACTIONS                   = "list_transactions"
+	QUERY_GET_COIN_HISTORY                    = "get_coin_history"
+	QUERY_LIST_LOCKED_COINS                   = "list_locked_coins"
+	QUERY_LIST_COINS_BY_VALUE                 = "list_coins_by_value"
+	QUERY_GET_COINS                           = "get_coins"
+	QUERY_GET_COINS_BY_OWNERS                 = "get_coins_by_owners"
+	QUERY_GET_DENOMINATIONS                   = "get_denominations"
+	QUERY_LIST_VALIDATORS                     = "list_validators"
+	QUERY_GET_STAKES                          = "get_stakes"
+	QUERY_LIST_SLASHES                        = "list_slashes"
 )
 
 func (tva *TMApplication) Query(qreq types.RequestQuery) types.ResponseQuery {
 	u, err := url.Parse(qreq.Path)
 	if err != nil {
-		return types.ResponseQuery{Code: models.CodeTypeEncodingError, Log: err.Error()}
+		return failedQuery(models.CodeTypeEncodingError, err)
 	}
+	if !qreq.Prove && qreq.Height == 0 {
+		return queryState(&tva.state, u)
+	}
+
+	// the proofs are only for committed versions, so the answer needs to be from the same version
+	height := qreq.Height
+	if height == 0 {
+		height = tva.state.Height
+	}
+	state, err := tva.state.AtVersion(height)
+	if err != nil {
+		return failedQuery(models.CodeTypeHeightNotAvailable, err)
+	}
+	resp := queryState(state, u)
+	if resp.Code != models.CodeTypeOK {
+		return resp
+	}
+	resp.Height = state.Height
+	if !qreq.Prove {
+		return resp
+	}
+	keys, err := provenKeys(u, resp.Value)
+	if err != nil {
+		return failedQuery(models.CodeTypeQueryNotExists, err)
+	}
+	kps, err := tva.state.Prove(state.Height, keys)
+	if err != nil {
+		return failedQuery(models.CodeTypeServerError, err)
+	}
+	resp.Proof, _ = json.Marshal(kps)
+	return resp
+}
 
+func queryState(s *dbpkg.State, u *url.URL) types.ResponseQuery {
 	switch u.Path {
 	case QUERY_GET_COIN:
-		qr, err := query.GetCoin(&tva.state, u)
+		qr, code, err := query.GetCoin(s, u)
 		if err != nil {
-			return types.ResponseQuery{Code: models.CodeTypeUnauthorized, Log: err.Error()}
+			return failedQuery(code, err)
 		}
 
 		b, _ := json.Marshal(qr)
 		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
 	case QUERY_GET_COIN_BY_OWNER:
-		qr, err := query.GetCoinByOwner(&tva.state, u)
+		qr, code, err := query.GetCoinByOwner(s, u)
 		if err != nil {
-			return types.ResponseQuery{Code: models.CodeTypeUnauthorized, Log: err.Error()}
+			return failedQuery(code, err)
 		}
 
 		b, _ := json.Marshal(qr)
 		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+	case QUERY_GET_COINS:
+		qcls, code, err := query.GetCoins(s, u)
+		if err != nil {
+			return failedQuery(code, err)
+		}
+
+		b, _ := json.Marshal(qcls)
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+	case QUERY_GET_COINS_BY_OWNERS:
+		qcls, code, err := query.GetCoinsByOwners(s, u)
+		if err != nil {
+			return failedQuery(code, err)
+		}
+
+		b, _ := json.Marshal(qcls)
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
 	case QUERY_GET_LATEST_TAX:
-		qt, err := query.GetLatestTax(&tva.state)
+		qt, code, err := query.GetLatestTax(s, u)
 		if err != nil {
-			return types.ResponseQuery{Code: models.CodeTypeUnauthorized, Log: err.Error()}
+			return failedQuery(code, err)
 		}
 
 		b, _ := json.Marshal(qt)
 		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+	case QUERY_GET_DENOMINATIONS:
+		b, _ := json.Marshal(query.GetDenominations(s, u))
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+	case QUERY_LIST_VALIDATORS:
+		b, _ := json.Marshal(query.ListValidators(s))
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+	case QUERY_GET_STAKES:
+		qmss, code, err := query.GetStakes(s, u)
+		if err != nil {
+			return failedQuery(code, err)
+		}
+		b, _ := json.Marshal(qmss)
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+	case QUERY_LIST_SLASHES:
+		b, _ := json.Marshal(query.ListSlashes(s, u))
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
 	case QUERY_GET_TRANSACTION:
-		qt, err := query.GetTransaction(&tva.state, u)
+		qt, code, err := query.GetTransaction(s, u)
 		if err != nil {
-			return types.ResponseQuery{Code: models.CodeTypeUnauthorized, Log: err.Error()}
+			return failedQuery(code, err)
 		}
 		b, _ := json.Marshal(qt)
 		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
 
 	case QUERY_GET_TRANSACTION_WITH_UNRECEIVED_FEE:
-		qts := query.GetTransactionsWithUnreceivedFee(&tva.state)
+		qts := query.GetTransactionsWithUnreceivedFee(s)
+		b, _ := json.Marshal(qts)
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+
+	case QUERY_GET_STEALTH_TRANSACTIONS:
+		qts := query.GetStealthTransactions(s)
 		b, _ := json.Marshal(qts)
 		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+
+	case QUERY_LIST_TRANSACTIONS:
+		qtl, code, err := query.ListTransactions(s, u)
+		if err != nil {
+			return failedQuery(code, err)
+		}
+		b, _ := json.Marshal(qtl)
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+
+	case QUERY_GET_COIN_HISTORY:
+		qchs, code, err := query.GetCoinHistory(s, u)
+		if err != nil {
+			return failedQuery(code, err)
+		}
+		b, _ := json.Marshal(qchs)
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+
+	case QUERY_LIST_LOCKED_COINS:
+		qcl, code, err := query.ListLockedCoins(s, u)
+		if err != nil {
+			return failedQuery(code, err)
+		}
+		b, _ := json.Marshal(qcl)
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
+
+	case QUERY_LIST_COINS_BY_VALUE:
+		qcl, code, err := query.ListCoinsByValue(s, u)
+		if err != nil {
+			return failedQuery(code, err)
+		}
+		b, _ := json.Marshal(qcl)
+		return types.ResponseQuery{Code: models.CodeTypeOK, Value: b}
 	}
-	return types.ResponseQuery{Code: models.CodeTypeUnauthorized,
-		Log: ERR_THE_QUERY_METHOD_HAS_NOT_BEEN_FOUND.Error()}
+	return failedQuery(models.CodeTypeQueryNotExists, ERR_THE_QUERY_METHOD_HAS_NOT_BEEN_FOUND)
+}
+
+// failedQuery returns the failed response of the query with the code of the catalogue
+func failedQuery(code uint32, err error) types.ResponseQuery {
+	return types.ResponseQuery{Code: code, Log: err.Error(), Codespace: models.CODESPACE}
+}
 
+// provenKeys returns the keys of the state that prove the answer of the query
+func provenKeys(u *url.URL, value []byte) ([][]byte, error) {
+	switch u.Path {
+	case QUERY_GET_COIN:
+		qr := query.QueryModelCoin{}
+		json.Unmarshal(value, &qr)
+		return [][]byte{dbpkg.CoinKey(qr.Coin)}, nil
+	case QUERY_GET_COIN_BY_OWNER:
+		qr := query.QueryModelCoin{}
+		json.Unmarshal(value, &qr)
+		return [][]byte{dbpkg.OwnerKey(qr.Owner), dbpkg.CoinKey(qr.Coin)}, nil
+	case QUERY_GET_LATEST_TAX:
+		return [][]byte{dbpkg.AssetTaxKey(u.Query().Get("asset"))}, nil
+	case QUERY_GET_TRANSACTION:
+		qt := query.QueryModelTransaction{}
+		json.Unmarshal(value, &qt)
+		return [][]byte{dbpkg.TransactionKey(qt.Hash)}, nil
+	}
+	return nil, ERR_THE_QUERY_CAN_NOT_BE_PROVEN
 }
@@ -3,8 +3,11 @@ package query
 import (
 	"errors"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 )
 
 type QueryModelCoin struct {
@@ -12,6 +15,8 @@ type QueryModelCoin struct {
 	Owner    string
 	IsLocked bool
 	Value    float64
+	Asset    string `json:",omitempty"`
+	Height   int64  `json:",omitempty"` // the height that the demurrage of the coin is counted from
 }
 
 var (
@@ -25,47 +30,120 @@ var (
 	}
 )
 
-func GetCoin(s *dbpkg.State, u *url.URL) (*QueryModelCoin, error) {
+func NewQueryModelCoin(uuid string, sc *dbpkg.StateCoin) QueryModelCoin {
+	qm := QueryModelCoin{}
+	qm.Coin = uuid
+	qm.Owner = sc.Owner
+	qm.Value = sc.Value
+	qm.IsLocked = sc.IsLocked
+	qm.Asset = sc.Asset
+	qm.Height = sc.Height
+	return qm
+}
+
+func GetCoin(s *dbpkg.State, u *url.URL) (*QueryModelCoin, uint32, error) {
 	values := u.Query()
 	uuid := values.Get("coin")
 	if len(uuid) == 0 {
-		return nil, ERR_COIN_HAS_NOT_BEEN_SUBMITTED
+		return nil, models.CodeTypeFieldEmpty, ERR_COIN_HAS_NOT_BEEN_SUBMITTED
 	}
 	sc, err := s.GetCoin(uuid)
 	if err != nil {
-		return nil, ERR_COIN_NOT_FOUND(uuid)
+		return nil, models.CodeTypeCoinNotFound, ERR_COIN_NOT_FOUND(uuid)
 	}
 
-	qm := QueryModelCoin{}
-	qm.Coin = uuid
-	qm.Owner = sc.Owner
-	qm.Value = sc.Value
-	qm.IsLocked = sc.IsLocked
-	return &qm, nil
+	qm := NewQueryModelCoin(uuid, sc)
+	return &qm, models.CodeTypeOK, nil
 }
 
-func GetCoinByOwner(s *dbpkg.State, u *url.URL) (*QueryModelCoin, error) {
+func GetCoinByOwner(s *dbpkg.State, u *url.URL) (*QueryModelCoin, uint32, error) {
 	values := u.Query()
 	owner := values.Get("owner")
 	if len(owner) == 0 {
-		return nil, ERR_OWNER_HAS_NOT_BEEN_SUBMITTED
+		return nil, models.CodeTypeFieldEmpty, ERR_OWNER_HAS_NOT_BEEN_SUBMITTED
 	}
 
 	coin, err := s.GetOwner(owner)
 	if err != nil {
-		return nil, ERR_OWNER_HAS_NOT_BEEN_FOUND(owner)
+		return nil, models.CodeTypeCoinNotFound, ERR_OWNER_HAS_NOT_BEEN_FOUND(owner)
 	}
 
 	sc, err := s.GetCoin(coin)
 	if err != nil {
-		return nil, ERR_COIN_NOT_FOUND(coin)
+		return nil, models.CodeTypeCoinNotFound, ERR_COIN_NOT_FOUND(coin)
 	}
 
-	qm := QueryModelCoin{}
-	qm.Coin = coin
-	qm.Owner = sc.Owner
-	qm.Value = sc.Value
-	qm.IsLocked = sc.IsLocked
+	qm := NewQueryModelCoin(coin, sc)
+	return &qm, models.CodeTypeOK, nil
+}
+
+// the most coins that a batch query can look up
+const GET_COINS_MAX = 1000
 
-	return &qm, nil
+// QueryModelCoinLookup is the result of one coin in a batch query
+type QueryModelCoinLookup struct {
+	Key      string // the uuid or the owner that has been looked up
+	NotFound bool
+	Coin     *QueryModelCoin
+}
+
+var (
+	ERR_COINS_HAVE_NOT_BEEN_SUBMITTED  = errors.New("The coins' UUIDs have not been submitted.")
+	ERR_OWNERS_HAVE_NOT_BEEN_SUBMITTED = errors.New("The owners' public keys have not been submitted.")
+	ERR_TOO_MANY_LOOKUPS               = errors.New("The query can not look up more than " + strconv.Itoa(GET_COINS_MAX) + " coins.")
+)
+
+// splitLookups returns the comma separated keys of the parameter
+func splitLookups(u *url.URL, name string, errEmpty error) ([]string, uint32, error) {
+	v := u.Query().Get(name)
+	if len(v) == 0 {
+		return nil, models.CodeTypeFieldEmpty, errEmpty
+	}
+	keys := strings.Split(v, ",")
+	if len(keys) > GET_COINS_MAX {
+		return nil, models.CodeTypeBadData, ERR_TOO_MANY_LOOKUPS
+	}
+	return keys, models.CodeTypeOK, nil
+}
+
+func GetCoins(s *dbpkg.State, u *url.URL) ([]QueryModelCoinLookup, uint32, error) {
+	uuids, code, err := splitLookups(u, "coins", ERR_COINS_HAVE_NOT_BEEN_SUBMITTED)
+	if err != nil {
+		return nil, code, err
+	}
+	qmcls := []QueryModelCoinLookup{}
+	for _, uuid := range uuids {
+		qmcl := QueryModelCoinLookup{Key: uuid}
+		sc, err := s.GetCoin(uuid)
+		if err != nil {
+			qmcl.NotFound = true
+		} else {
+			qm := NewQueryModelCoin(uuid, sc)
+			qmcl.Coin = &qm
+		}
+		qmcls = append(qmcls, qmcl)
+	}
+	return qmcls, models.CodeTypeOK, nil
+}
+
+func GetCoinsByOwners(s *dbpkg.State, u *url.URL) ([]QueryModelCoinLookup, uint32, error) {
+	owners, code, err := splitLookups(u, "owners", ERR_OWNERS_HAVE_NOT_BEEN_SUBMITTED)
+	if err != nil {
+		return nil, code, err
+	}
+	qmcls := []QueryModelCoinLookup{}
+	for _, owner := range owners {
+		qmcl := QueryModelCoinLookup{Key: owner, NotFound: true}
+		coin, err := s.GetOwner(owner)
+		if err == nil {
+			sc, err := s.GetCoin(coin)
+			if err == nil {
+				qm := NewQueryModelCoin(coin, sc)
+				qmcl.Coin = &qm
+				qmcl.NotFound = false
+			}
+		}
+		qmcls = append(qmcls, qmcl)
+	}
+	return qmcls, models.CodeTypeOK, nil
 }
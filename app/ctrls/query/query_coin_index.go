@@ -0,0 +1,95 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+)
+
+type QueryModelCoinHistory struct {
+	Height        int64
+	Action        string
+	PreviousOwner string
+	NewOwner      string
+}
+
+type QueryModelCoinList struct {
+	Coins []QueryModelCoin
+	Next  string // the cursor for the next page, it is empty on the last page
+}
+
+var (
+	ERR_COIN_HAS_NO_HISTORY = func(uuid string) error {
+		return errors.New("The coin " + uuid + " has no history.")
+	}
+	ERR_VALUE_HAS_NOT_BEEN_SUBMITTED = errors.New("The value of the coins has not been submitted.")
+	ERR_VALUE_IS_NOT_CORRECT         = errors.New("The value of the coins needs to be a positive number.")
+)
+
+func GetCoinHistory(s *dbpkg.State, u *url.URL) ([]QueryModelCoinHistory, uint32, error) {
+	values := u.Query()
+	uuid := values.Get("coin")
+	if len(uuid) == 0 {
+		return nil, models.CodeTypeFieldEmpty, ERR_COIN_HAS_NOT_BEEN_SUBMITTED
+	}
+	chs := s.GetCoinHistory(uuid)
+	if len(chs) == 0 {
+		return nil, models.CodeTypeCoinNotFound, ERR_COIN_HAS_NO_HISTORY(uuid)
+	}
+	qmchs := []QueryModelCoinHistory{}
+	for _, ch := range chs {
+		qmchs = append(qmchs, QueryModelCoinHistory{
+			Height:        ch.Height,
+			Action:        ch.Action,
+			PreviousOwner: ch.PreviousOwner,
+			NewOwner:      ch.NewOwner,
+		})
+	}
+	return qmchs, models.CodeTypeOK, nil
+}
+
+// listCoins fills a page with the coins that the iterate gives, in the order of their UUID
+func listCoins(s *dbpkg.State, values url.Values, iterate func(after string, fn func(uuid string) bool)) (*QueryModelCoinList, uint32, error) {
+	limit, err := parseLimit(values)
+	if err != nil {
+		return nil, models.CodeTypeBadData, err
+	}
+	qmcl := QueryModelCoinList{Coins: []QueryModelCoin{}}
+	iterate(values.Get("after"), func(uuid string) bool {
+		sc, err := s.GetCoin(uuid)
+		if err != nil {
+			return true
+		}
+		// there is one more coin after the page is full
+		if len(qmcl.Coins) == limit {
+			qmcl.Next = qmcl.Coins[limit-1].Coin
+			return false
+		}
+		qmcl.Coins = append(qmcl.Coins, NewQueryModelCoin(uuid, sc))
+		return true
+	})
+	return &qmcl, models.CodeTypeOK, nil
+}
+
+func ListLockedCoins(s *dbpkg.State, u *url.URL) (*QueryModelCoinList, uint32, error) {
+	return listCoins(s, u.Query(), s.IterateLockedCoins)
+}
+
+// ListCoinsByValue lists the coins of the value and of the asset, or of the main currency when the asset is empty
+func ListCoinsByValue(s *dbpkg.State, u *url.URL) (*QueryModelCoinList, uint32, error) {
+	values := u.Query()
+	v := values.Get("value")
+	if len(v) == 0 {
+		return nil, models.CodeTypeFieldEmpty, ERR_VALUE_HAS_NOT_BEEN_SUBMITTED
+	}
+	value, err := strconv.ParseFloat(v, 64)
+	if err != nil || value <= 0 {
+		return nil, models.CodeTypeBadData, ERR_VALUE_IS_NOT_CORRECT
+	}
+	return listCoins(s, values, func(after string, fn func(uuid string) bool) {
+		s.IterateCoinsByValue(values.Get("asset"), value, after, fn)
+	})
+}
@@ -0,0 +1,18 @@
+package query
+
+import (
+	"net/url"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+)
+
+type QueryModelDenominations struct {
+	Denominations []float64
+}
+
+// GetDenominations returns the denominations of the asset, or of the network when the asset is empty
+func GetDenominations(s *dbpkg.State, u *url.URL) *QueryModelDenominations {
+	qmd := QueryModelDenominations{}
+	qmd.Denominations = s.GetAssetDenominations(u.Query().Get("asset"))
+	return &qmd
+}
@@ -0,0 +1,162 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+)
+
+const (
+	LIST_DEFAULT_LIMIT = 20
+	LIST_MAX_LIMIT     = 100
+)
+
+type QueryModelTransactionList struct {
+	Transactions []QueryModelTransaction
+	Next         string // the cursor for the next page, it is empty on the last page
+}
+
+var (
+	ERR_LIMIT_IS_NOT_CORRECT = errors.New("The limit needs to be a number from 1 to " + strconv.Itoa(LIST_MAX_LIMIT) + ".")
+	ERR_FILTER_IS_NOT_BOOL   = func(name string) error {
+		return errors.New("The filter " + name + " needs to be true or false.")
+	}
+	ERR_FILTER_IS_NOT_HEIGHT = func(name string) error {
+		return errors.New("The filter " + name + " needs to be a positive height.")
+	}
+)
+
+// transactionFilter keeps only the transactions that match all of its filters,
+// the nil and the empty filters match everything
+type transactionFilter struct {
+	feeReceived   *bool
+	coinsReceived *bool
+	coin          string
+	fromHeight    int64
+	toHeight      int64
+}
+
+func (tf *transactionFilter) match(st *dbpkg.StateTransaction) bool {
+	if tf.feeReceived != nil && *tf.feeReceived != st.IsFeeReceived {
+		return false
+	}
+	if tf.coinsReceived != nil && *tf.coinsReceived != st.IsCoinsReceived {
+		return false
+	}
+	if len(tf.coin) > 0 && !containsCoin(st, tf.coin) {
+		return false
+	}
+	if tf.fromHeight > 0 && st.Height < tf.fromHeight {
+		return false
+	}
+	if tf.toHeight > 0 && st.Height > tf.toHeight {
+		return false
+	}
+	return true
+}
+
+func containsCoin(st *dbpkg.StateTransaction, coin string) bool {
+	for _, v := range st.Coins {
+		if v == coin {
+			return true
+		}
+	}
+	for _, v := range st.Fee {
+		if v == coin {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLimit returns how many items a page of a list has
+func parseLimit(values url.Values) (int, error) {
+	v := values.Get("limit")
+	if len(v) == 0 {
+		return LIST_DEFAULT_LIMIT, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > LIST_MAX_LIMIT {
+		return 0, ERR_LIMIT_IS_NOT_CORRECT
+	}
+	return limit, nil
+}
+
+func parseBoolFilter(values url.Values, name string) (*bool, error) {
+	v := values.Get(name)
+	if len(v) == 0 {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, ERR_FILTER_IS_NOT_BOOL(name)
+	}
+	return &b, nil
+}
+
+func parseHeightFilter(values url.Values, name string) (int64, error) {
+	v := values.Get(name)
+	if len(v) == 0 {
+		return 0, nil
+	}
+	h, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || h < 1 {
+		return 0, ERR_FILTER_IS_NOT_HEIGHT(name)
+	}
+	return h, nil
+}
+
+func parseTransactionFilter(values url.Values) (*transactionFilter, error) {
+	var err error
+	tf := transactionFilter{}
+	tf.feeReceived, err = parseBoolFilter(values, "fee_received")
+	if err != nil {
+		return nil, err
+	}
+	tf.coinsReceived, err = parseBoolFilter(values, "coins_received")
+	if err != nil {
+		return nil, err
+	}
+	tf.coin = values.Get("coin")
+	tf.fromHeight, err = parseHeightFilter(values, "from_height")
+	if err != nil {
+		return nil, err
+	}
+	tf.toHeight, err = parseHeightFilter(values, "to_height")
+	if err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// ListTransactions returns a page of the transactions that match the filters.
+// The pages are in the order of the hashes, and the next page starts after the hash of the cursor.
+func ListTransactions(s *dbpkg.State, u *url.URL) (*QueryModelTransactionList, uint32, error) {
+	values := u.Query()
+	limit, err := parseLimit(values)
+	if err != nil {
+		return nil, models.CodeTypeBadData, err
+	}
+	tf, err := parseTransactionFilter(values)
+	if err != nil {
+		return nil, models.CodeTypeBadData, err
+	}
+
+	qmtl := QueryModelTransactionList{Transactions: []QueryModelTransaction{}}
+	s.IterateTransactions(values.Get("after"), func(hash string, st *dbpkg.StateTransaction) bool {
+		if !tf.match(st) {
+			return true
+		}
+		// there is one more transaction after the page is full
+		if len(qmtl.Transactions) == limit {
+			qmtl.Next = qmtl.Transactions[limit-1].Hash
+			return false
+		}
+		qmtl.Transactions = append(qmtl.Transactions, NewQueryModelTransaction(hash, st))
+		return true
+	})
+	return &qmtl, models.CodeTypeOK, nil
+}
@@ -0,0 +1,36 @@
+package query
+
+import (
+	"net/url"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+)
+
+type QueryModelSlash struct {
+	Validator      string
+	Height         int64
+	EvidenceHeight int64
+	Type           string
+	Percentage     int
+	Coins          []string
+	Value          float64
+	Hash           string `json:",omitempty"` // the transaction that the inflators retrieve the coins from
+}
+
+// ListSlashes returns the slashes of the validator, or of all the validators without the validator
+func ListSlashes(s *dbpkg.State, u *url.URL) []QueryModelSlash {
+	qmss := []QueryModelSlash{}
+	for _, sl := range s.GetSlashes(u.Query().Get("validator")) {
+		qmss = append(qmss, QueryModelSlash{
+			Validator:      sl.Validator,
+			Height:         sl.Height,
+			EvidenceHeight: sl.EvidenceHeight,
+			Type:           sl.Type,
+			Percentage:     sl.Percentage,
+			Coins:          sl.Coins,
+			Value:          sl.Value,
+			Hash:           sl.Hash,
+		})
+	}
+	return qmss
+}
@@ -2,8 +2,10 @@ package query
 
 import (
 	"errors"
+	"net/url"
 
 	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 )
 
 var (
@@ -13,15 +15,25 @@ var (
 type QueryModelTax struct {
 	Percentage int
 	Inflator   string
+	Asset      string `json:",omitempty"`
+	Demurrage  int    `json:",omitempty"`
+	// the height that the demurrage is counted from, for the coins that are older
+	DemurrageHeight int64 `json:",omitempty"`
 }
 
-func GetLatestTax(s *dbpkg.State) (*QueryModelTax, error) {
-	st := s.GetTax()
+// GetLatestTax returns the latest tax of the asset, or of the main currency when the asset is empty
+func GetLatestTax(s *dbpkg.State, u *url.URL) (*QueryModelTax, uint32, error) {
+	st := s.GetAssetTax(u.Query().Get("asset"))
 	if len(st.Inflator) == 0 {
-		return nil, ERR_THERE_NO_TAXES
+		return nil, models.CodeTypeNotFound, ERR_THERE_NO_TAXES
 	}
 	qmt := QueryModelTax{}
 	qmt.Inflator = st.Inflator
 	qmt.Percentage = st.Percentage
-	return &qmt, nil
+	qmt.Asset = st.Asset
+	qmt.Demurrage = st.Demurrage
+	if st.Demurrage > 0 {
+		qmt.DemurrageHeight = s.GetDemurrageHeight(st.Asset)
+	}
+	return &qmt, models.CodeTypeOK, nil
 }
@@ -1,21 +1,27 @@
 package query
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"net/url"
 
 	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 )
 
 type QueryModelTransaction struct {
 	Hash            string
 	Coins           []string
 	Fee             []string
+	Memo            string
+	EncryptedMemo   string
+	EncryptedSecret string
+	Recipient       string
+	StealthR        string
+	StealthOwners   map[string]string
+	ReceivedCoins   []string
 	IsFeeReceived   bool
 	IsCoinsReceived bool
+	Height          int64
 }
 
 var (
@@ -25,43 +31,60 @@ var (
 	}
 )
 
-func GetTransaction(s *dbpkg.State, u *url.URL) (*QueryModelTransaction, error) {
+func NewQueryModelTransaction(hash string, st *dbpkg.StateTransaction) QueryModelTransaction {
+	qmt := QueryModelTransaction{}
+	qmt.Coins = st.Coins
+	qmt.Fee = st.Fee
+	qmt.Memo = st.Memo
+	qmt.EncryptedMemo = st.EncryptedMemo
+	qmt.EncryptedSecret = st.EncryptedSecret
+	qmt.Recipient = st.Recipient
+	qmt.StealthR = st.StealthR
+	qmt.StealthOwners = st.StealthOwners
+	qmt.ReceivedCoins = st.ReceivedCoins
+	qmt.Hash = hash
+	qmt.IsCoinsReceived = st.IsCoinsReceived
+	qmt.IsFeeReceived = st.IsFeeReceived
+	qmt.Height = st.Height
+	return qmt
+}
+
+func GetTransaction(s *dbpkg.State, u *url.URL) (*QueryModelTransaction, uint32, error) {
 	values := u.Query()
 	hash := values.Get("hash")
 	if len(hash) == 0 {
-		return nil, ERR_TRANSACTION_HAS_NOT_BEEN_SUBMITTED
+		return nil, models.CodeTypeFieldEmpty, ERR_TRANSACTION_HAS_NOT_BEEN_SUBMITTED
 	}
 	st, err := s.GetTransaction(hash)
 	if err != nil {
-		return nil, ERR_TRANSACTION_HAS_NOT_BEEN_FOUND(hash)
+		return nil, models.CodeTypeTransactionNotFound, ERR_TRANSACTION_HAS_NOT_BEEN_FOUND(hash)
 	}
-	qmt := QueryModelTransaction{}
-	qmt.Coins = st.Coins
-	qmt.Fee = st.Fee
-	qmt.Hash = hash
-	qmt.IsCoinsReceived = st.IsCoinsReceived
-	qmt.IsFeeReceived = st.IsFeeReceived
-	return &qmt, nil
+	qmt := NewQueryModelTransaction(hash, st)
+	return &qmt, models.CodeTypeOK, nil
 }
 
+// GetTransactionsWithUnreceivedFee returns the transactions that their fee has not been retrieved,
+// with the transactions of the coins that have been slashed from the validators
 func GetTransactionsWithUnreceivedFee(s *dbpkg.State) []QueryModelTransaction {
-	sts := s.GetTransactions()
 	qmts := []QueryModelTransaction{}
-	for _, st := range sts {
+	s.IterateTransactions("", func(hash string, st *dbpkg.StateTransaction) bool {
 		if !st.IsFeeReceived {
-			qmt := QueryModelTransaction{}
-			qmt.Coins = st.Coins
-			qmt.Fee = st.Fee
-
-			msg, _ := json.Marshal(st.Coins)
-			hash := sha256.Sum256(msg)
-			hashHex := hex.EncodeToString(hash[:])
+			qmts = append(qmts, NewQueryModelTransaction(hash, st))
+		}
+		return true
+	})
+	return qmts
+}
 
-			qmt.Hash = hashHex
-			qmt.IsCoinsReceived = st.IsCoinsReceived
-			qmt.IsFeeReceived = st.IsFeeReceived
-			qmts = append(qmts, qmt)
+// GetStealthTransactions returns the transactions to stealth addresses,
+// so the wallets can scan them for the coins that they own
+func GetStealthTransactions(s *dbpkg.State) []QueryModelTransaction {
+	qmts := []QueryModelTransaction{}
+	s.IterateTransactions("", func(hash string, st *dbpkg.StateTransaction) bool {
+		if st.IsStealth() {
+			qmts = append(qmts, NewQueryModelTransaction(hash, st))
 		}
-	}
+		return true
+	})
 	return qmts
 }
@@ -0,0 +1,57 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+)
+
+type QueryModelValidator struct {
+	PubKey      string
+	Power       int64   // the power that the inflators have set
+	Staked      float64 `json:",omitempty"`
+	VotingPower int64
+}
+
+type QueryModelStake struct {
+	Coin            string
+	Value           float64
+	UnbondingHeight int64 `json:",omitempty"`
+}
+
+var (
+	ERR_VALIDATOR_HAS_NOT_BEEN_SUBMITTED = errors.New("The validator has not been submitted.")
+)
+
+// ListValidators returns the validators that the inflators have set or the coins have been staked to,
+// without the validators that have been removed
+func ListValidators(s *dbpkg.State) []QueryModelValidator {
+	qmvs := []QueryModelValidator{}
+	for _, sv := range s.GetValidators() {
+		if sv.Power == 0 && sv.Staked == 0 {
+			continue
+		}
+		qmvs = append(qmvs, QueryModelValidator{
+			PubKey:      sv.PubKey,
+			Power:       sv.Power,
+			Staked:      sv.Staked,
+			VotingPower: sv.VotingPower(),
+		})
+	}
+	return qmvs
+}
+
+// GetStakes returns the coins that have been staked to the validator, with the coins that are unbonding
+func GetStakes(s *dbpkg.State, u *url.URL) ([]QueryModelStake, uint32, error) {
+	validator := u.Query().Get("validator")
+	if len(validator) == 0 {
+		return nil, models.CodeTypeFieldEmpty, ERR_VALIDATOR_HAS_NOT_BEEN_SUBMITTED
+	}
+	qmss := []QueryModelStake{}
+	for _, ss := range s.GetValidatorStakes(validator) {
+		qmss = append(qmss, QueryModelStake{Coin: ss.Coin, Value: ss.Value, UnbondingHeight: ss.UnbondingHeight})
+	}
+	return qmss, models.CodeTypeOK, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/dedis/kyber/proof/dleq"
 	"github.com/dedis/kyber/util/random"
 	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 	"github.com/mragiadakos/tendermoney/app/ctrls/query"
 	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
@@ -29,7 +30,7 @@ func TestQueryCoinFailOnNotFound(t *testing.T) {
 	qreq.Path = QUERY_GET_COIN + "?coin=" + uuid
 
 	resp := app.Query(qreq)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinNotFound, resp.Code)
 	assert.Equal(t, query.ERR_COIN_NOT_FOUND(uuid), errors.New(resp.Log))
 }
 
@@ -39,7 +40,7 @@ func TestQueryCoinFailOnCoinNotSubmitted(t *testing.T) {
 	qreq := types.RequestQuery{}
 	qreq.Path = QUERY_GET_COIN
 	resp := app.Query(qreq)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, query.ERR_COIN_HAS_NOT_BEEN_SUBMITTED, errors.New(resp.Log))
 }
 
@@ -72,7 +73,7 @@ func TestQueryCoinByOwnerFailPublicIsEmpty(t *testing.T) {
 	qreq := types.RequestQuery{}
 	qreq.Path = QUERY_GET_COIN_BY_OWNER
 	resp := app.Query(qreq)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, query.ERR_OWNER_HAS_NOT_BEEN_SUBMITTED, errors.New(resp.Log))
 }
 
@@ -83,7 +84,7 @@ func TestQueryCoinByOwnerFailPublicIsNotFound(t *testing.T) {
 	owner := "blalalla"
 	qreq.Path = QUERY_GET_COIN_BY_OWNER + "?owner=" + owner
 	resp := app.Query(qreq)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeCoinNotFound, resp.Code)
 	assert.Equal(t, query.ERR_OWNER_HAS_NOT_BEEN_FOUND(owner), errors.New(resp.Log))
 }
 
@@ -116,7 +117,7 @@ func TestQueryTaxFailOnNoTaxes(t *testing.T) {
 	qreq := types.RequestQuery{}
 	qreq.Path = QUERY_GET_LATEST_TAX
 	resp := app.Query(qreq)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeNotFound, resp.Code)
 	assert.Equal(t, query.ERR_THERE_NO_TAXES, errors.New(resp.Log))
 }
 
@@ -146,7 +147,7 @@ func TestQueryTransactionFailOnHashIsEmpty(t *testing.T) {
 	qreq := types.RequestQuery{}
 	qreq.Path = QUERY_GET_TRANSACTION
 	resp := app.Query(qreq)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
 	assert.Equal(t, query.ERR_TRANSACTION_HAS_NOT_BEEN_SUBMITTED, errors.New(resp.Log))
 }
 
@@ -156,7 +157,7 @@ func TestQueryTransactionFailOnHashNotFound(t *testing.T) {
 	hash := "blblblblblb"
 	qreq.Path = QUERY_GET_TRANSACTION + "?hash=" + hash
 	resp := app.Query(qreq)
-	assert.Equal(t, models.CodeTypeUnauthorized, resp.Code)
+	assert.Equal(t, models.CodeTypeTransactionNotFound, resp.Code)
 	assert.Equal(t, query.ERR_TRANSACTION_HAS_NOT_BEEN_FOUND(hash), errors.New(resp.Log))
 }
 
@@ -196,6 +197,7 @@ func TestQueryTransactionSuccess(t *testing.T) {
 	assert.Equal(t, hashHex, qmt.Hash)
 	assert.Equal(t, coins, qmt.Coins)
 	assert.Equal(t, []string{}, qmt.Fee)
+	assert.Equal(t, "", qmt.Memo)
 	assert.False(t, qmt.IsCoinsReceived)
 	assert.False(t, qmt.IsFeeReceived)
 }
@@ -236,3 +238,347 @@ func TestGetUnreceivedFeeTransactionsSuccess(t *testing.T) {
 	json.Unmarshal(resp.Value, &qts)
 	assert.Equal(t, 2, len(qts))
 }
+
+func TestQueryProveFailOnNothingCommitted(t *testing.T) {
+	app := NewTMApplication()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_LATEST_TAX
+	qreq.Prove = true
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeHeightNotAvailable, resp.Code)
+	assert.Equal(t, dbpkg.ERR_VERSION_DOES_NOT_EXIST(0), errors.New(resp.Log))
+}
+
+func TestQueryProveFailOnQueryCanNotBeProven(t *testing.T) {
+	app := NewTMApplication()
+	app.Commit()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_TRANSACTION_WITH_UNRECEIVED_FEE
+	qreq.Prove = true
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeQueryNotExists, resp.Code)
+	assert.Equal(t, ERR_THE_QUERY_CAN_NOT_BE_PROVEN, errors.New(resp.Log))
+}
+
+func TestQueryCoinProveSuccess(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin, _ := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+	app.Commit()
+	// the changes after the commit are not part of the proven answer
+	newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_COIN + "?coin=" + coin
+	qreq.Prove = true
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	assert.Equal(t, int64(1), resp.Height)
+
+	kps := []dbpkg.KeyProof{}
+	err := json.Unmarshal(resp.Proof, &kps)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(kps))
+	assert.Equal(t, dbpkg.CoinKey(coin), kps[0].Key)
+	assert.Nil(t, kps[0].Proof.Verify(app.state.AppHash))
+	assert.Nil(t, kps[0].Proof.VerifyItem(kps[0].Key, kps[0].Value))
+
+	sc := dbpkg.StateCoin{}
+	json.Unmarshal(kps[0].Value, &sc)
+	assert.Equal(t, 0.50, sc.Value)
+}
+
+func TestQueryTaxAtHeightSuccess(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	createTax(t, app, inflatorKp, inflatorPubHex, 23)
+	app.Commit()
+	createTax(t, app, inflatorKp, inflatorPubHex, 10)
+	app.Commit()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_LATEST_TAX
+	qreq.Height = 1
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	assert.Equal(t, int64(1), resp.Height)
+
+	qmt := query.QueryModelTax{}
+	json.Unmarshal(resp.Value, &qmt)
+	assert.Equal(t, 23, qmt.Percentage)
+
+	qreq.Height = 2
+	resp = app.Query(qreq)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	json.Unmarshal(resp.Value, &qmt)
+	assert.Equal(t, 10, qmt.Percentage)
+}
+
+func TestQueryAtHeightFailOnHeightNotCommitted(t *testing.T) {
+	app := NewTMApplication()
+	app.Commit()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_LATEST_TAX
+	qreq.Height = 2
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeHeightNotAvailable, resp.Code)
+	assert.Equal(t, dbpkg.ERR_VERSION_DOES_NOT_EXIST(2), errors.New(resp.Log))
+}
+
+func TestQueryAtHeightFailOnPrunedHeight(t *testing.T) {
+	app := NewTMApplication()
+	app.state.KeepVersions = 2
+	app.Commit()
+	app.Commit()
+	app.Commit()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_LATEST_TAX
+	qreq.Height = 1
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeHeightNotAvailable, resp.Code)
+	assert.Equal(t, dbpkg.ERR_VERSION_HAS_BEEN_PRUNED(1), errors.New(resp.Log))
+}
+
+func TestQueryAtHeightPrunesAllTheOlderHeights(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	createTax(t, app, inflatorKp, inflatorPubHex, 23)
+
+	app.state.KeepVersions = 4
+	for i := 0; i < 5; i++ {
+		app.Commit()
+	}
+
+	// keeping fewer versions prunes all the versions before them, not only the latest one
+	app.state.KeepVersions = 1
+	app.Commit()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_LATEST_TAX
+	for height := int64(1); height < 6; height++ {
+		qreq.Height = height
+		resp := app.Query(qreq)
+		assert.Equal(t, models.CodeTypeHeightNotAvailable, resp.Code)
+		assert.Equal(t, dbpkg.ERR_VERSION_HAS_BEEN_PRUNED(height), errors.New(resp.Log))
+	}
+	qreq.Height = 6
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	assert.Equal(t, int64(6), resp.Height)
+}
+
+func TestListTransactionsFailOnLimitNotCorrect(t *testing.T) {
+	app := NewTMApplication()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_LIST_TRANSACTIONS + "?limit=0"
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeBadData, resp.Code)
+	assert.Equal(t, query.ERR_LIMIT_IS_NOT_CORRECT, errors.New(resp.Log))
+}
+
+func TestListTransactionsFailOnFilterNotBool(t *testing.T) {
+	app := NewTMApplication()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_LIST_TRANSACTIONS + "?fee_received=maybe"
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeBadData, resp.Code)
+	assert.Equal(t, query.ERR_FILTER_IS_NOT_BOOL("fee_received"), errors.New(resp.Log))
+}
+
+func TestListTransactionsSuccess(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	createTax(t, app, inflatorKp, inflatorPubHex, 23)
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	firstCoin := ""
+	for i := 0; i < 3; i++ {
+		coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.01)
+		fee, feeKp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.01)
+		if i == 0 {
+			firstCoin = coin
+		}
+
+		rng := random.New()
+		x := suite.Scalar().Pick(rng)
+		g := suite.Point().Pick(rng)
+		h := suite.Point().Pick(rng)
+
+		coins := []string{coin}
+		fees := []string{fee}
+		proof, _, _, _ := dleq.NewDLEQProof(suite, g, h, x)
+		transact(t, app, coins, fees, proof, []kyber.Scalar{coinKp.Private, feeKp.Private})
+		if i == 0 {
+			receivedFee(t, app, inflatorKp, inflatorPubHex, coins, fees)
+		}
+		// every transaction is on its own block
+		app.Commit()
+	}
+
+	list := func(params string) query.QueryModelTransactionList {
+		qreq := types.RequestQuery{}
+		qreq.Path = QUERY_LIST_TRANSACTIONS + "?" + params
+		resp := app.Query(qreq)
+		assert.Equal(t, models.CodeTypeOK, resp.Code)
+		qtl := query.QueryModelTransactionList{}
+		json.Unmarshal(resp.Value, &qtl)
+		return qtl
+	}
+
+	// the pages
+	qtl := list("limit=2")
+	assert.Equal(t, 2, len(qtl.Transactions))
+	assert.Equal(t, qtl.Transactions[1].Hash, qtl.Next)
+	qtl2 := list("limit=2&after=" + qtl.Next)
+	assert.Equal(t, 1, len(qtl2.Transactions))
+	assert.Equal(t, "", qtl2.Next)
+	assert.True(t, qtl2.Transactions[0].Hash > qtl.Next)
+
+	// the filters
+	assert.Equal(t, 2, len(list("fee_received=false").Transactions))
+	assert.Equal(t, 1, len(list("fee_received=true").Transactions))
+	assert.Equal(t, 3, len(list("coins_received=false").Transactions))
+	assert.Equal(t, 1, len(list("coin="+firstCoin).Transactions))
+	assert.Equal(t, 2, len(list("from_height=2").Transactions))
+	assert.Equal(t, 1, len(list("from_height=2&to_height=2").Transactions))
+}
+
+func TestQueryCoinHistoryFailOnNoHistory(t *testing.T) {
+	app := NewTMApplication()
+
+	coin := uuid.NewV4().String()
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_COIN_HISTORY + "?coin=" + coin
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeCoinNotFound, resp.Code)
+	assert.Equal(t, query.ERR_COIN_HAS_NO_HISTORY(coin), errors.New(resp.Log))
+}
+
+func TestQueryCoinIndexesSuccess(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	createTax(t, app, inflatorKp, inflatorPubHex, 23)
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.5)
+	fee, feeKp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.5)
+	newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	app.Commit()
+
+	rng := random.New()
+	x := suite.Scalar().Pick(rng)
+	g := suite.Point().Pick(rng)
+	h := suite.Point().Pick(rng)
+	proof, _, _, _ := dleq.NewDLEQProof(suite, g, h, x)
+	transact(t, app, []string{coin}, []string{fee}, proof, []kyber.Scalar{coinKp.Private, feeKp.Private})
+
+	get := func(path string, v interface{}) {
+		qreq := types.RequestQuery{}
+		qreq.Path = path
+		resp := app.Query(qreq)
+		assert.Equal(t, models.CodeTypeOK, resp.Code)
+		json.Unmarshal(resp.Value, v)
+	}
+
+	qchs := []query.QueryModelCoinHistory{}
+	get(QUERY_GET_COIN_HISTORY+"?coin="+coin, &qchs)
+	assert.Equal(t, 2, len(qchs))
+	assert.Equal(t, dbpkg.COIN_INFLATED, qchs[0].Action)
+	assert.Equal(t, int64(1), qchs[0].Height)
+	ownerB, _ := coinKp.Public.MarshalBinary()
+	assert.Equal(t, hex.EncodeToString(ownerB), qchs[0].NewOwner)
+	assert.Equal(t, dbpkg.COIN_SENT, qchs[1].Action)
+	assert.Equal(t, int64(2), qchs[1].Height)
+
+	qcl := query.QueryModelCoinList{}
+	get(QUERY_LIST_LOCKED_COINS, &qcl)
+	assert.Equal(t, 2, len(qcl.Coins))
+	for _, qmc := range qcl.Coins {
+		assert.True(t, qmc.IsLocked)
+	}
+
+	qcl = query.QueryModelCoinList{}
+	get(QUERY_LIST_COINS_BY_VALUE+"?value=0.5&limit=1", &qcl)
+	assert.Equal(t, 1, len(qcl.Coins))
+	assert.Equal(t, qcl.Coins[0].Coin, qcl.Next)
+	qcl2 := query.QueryModelCoinList{}
+	get(QUERY_LIST_COINS_BY_VALUE+"?value=0.5&limit=1&after="+qcl.Next, &qcl2)
+	assert.Equal(t, 1, len(qcl2.Coins))
+	assert.Equal(t, "", qcl2.Next)
+	assert.Equal(t, 0.5, qcl2.Coins[0].Value)
+}
+
+func TestQueryCoinsFailOnCoinsNotSubmitted(t *testing.T) {
+	app := NewTMApplication()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_COINS
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeFieldEmpty, resp.Code)
+	assert.Equal(t, query.ERR_COINS_HAVE_NOT_BEEN_SUBMITTED, errors.New(resp.Log))
+}
+
+func TestQueryCoinsSuccess(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin1, _ := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+	coin2, _ := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	missing := uuid.NewV4().String()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_COINS + "?coins=" + coin1 + "," + missing + "," + coin2
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	qmcls := []query.QueryModelCoinLookup{}
+	json.Unmarshal(resp.Value, &qmcls)
+	assert.Equal(t, 3, len(qmcls))
+	assert.Equal(t, coin1, qmcls[0].Key)
+	assert.Equal(t, 0.50, qmcls[0].Coin.Value)
+	assert.Equal(t, missing, qmcls[1].Key)
+	assert.True(t, qmcls[1].NotFound)
+	assert.Nil(t, qmcls[1].Coin)
+	assert.Equal(t, 1.0, qmcls[2].Coin.Value)
+}
+
+func TestQueryCoinsByOwnersSuccess(t *testing.T) {
+	app := NewTMApplication()
+
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+
+	coin, coinKp := newCoin(t, app, inflatorKp, inflatorPubHex, 0.50)
+	ownerB, _ := coinKp.Public.MarshalBinary()
+	owner := hex.EncodeToString(ownerB)
+	_, missing := utils.CreateKeyPair()
+
+	qreq := types.RequestQuery{}
+	qreq.Path = QUERY_GET_COINS_BY_OWNERS + "?owners=" + missing + "," + owner
+	resp := app.Query(qreq)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	qmcls := []query.QueryModelCoinLookup{}
+	json.Unmarshal(resp.Value, &qmcls)
+	assert.Equal(t, 2, len(qmcls))
+	assert.True(t, qmcls[0].NotFound)
+	assert.Equal(t, owner, qmcls[1].Key)
+	assert.Equal(t, coin, qmcls[1].Coin.Coin)
+}
@@ -0,0 +1,98 @@
+package ctrls
+
+import (
+	"encoding/hex"
+	"sort"
+	"strconv"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/tendermint/abci/types"
+	cmn "github.com/tendermint/tmlibs/common"
+)
+
+// BeginBlock slashes the validators that the evidence of the block has found misbehaving,
+// and it returns a tag for every slash
+func (app *TMApplication) BeginBlock(req types.RequestBeginBlock) types.ResponseBeginBlock {
+	tags := []cmn.KVPair{}
+	for _, ev := range req.ByzantineValidators {
+		pub, err := evidenceValidator(&app.state, ev.Validator)
+		if err != nil {
+			continue
+		}
+		// the same misbehavior can be in the evidence of more blocks
+		if app.state.HasSlash(pub, ev.Height) {
+			continue
+		}
+		sl := slashValidator(&app.state, pub, ev)
+		tags = append(tags,
+			cmn.KVPair{Key: []byte("slash.validator"), Value: []byte(sl.Validator)},
+			cmn.KVPair{Key: []byte("slash.value"), Value: []byte(strconv.FormatFloat(sl.Value, 'f', -1, 64))},
+		)
+		if len(sl.Hash) > 0 {
+			tags = append(tags, cmn.KVPair{Key: []byte("slash.hash"), Value: []byte(sl.Hash)})
+		}
+	}
+	return types.ResponseBeginBlock{Tags: tags}
+}
+
+// evidenceValidator returns the public key hex of the validator of the evidence,
+// tendermint sends the address of the validator without its public key
+func evidenceValidator(state *dbpkg.State, v types.Validator) (string, error) {
+	if len(v.PubKey.Data) > 0 {
+		pub := hex.EncodeToString(v.PubKey.Data)
+		_, err := state.GetValidator(pub)
+		return pub, err
+	}
+	return state.GetValidatorByAddress(v.Address)
+}
+
+// slashValidator confiscates the slash percentage of the coins that have been staked to the validator,
+// with the coins that are unbonding. The coins are whole, so the biggest coins that fit in the percentage are taken first
+// and the rest that does not fit in a coin is taken with the smallest coin left, so a misbehavior is never free.
+func slashValidator(state *dbpkg.State, pub string, ev types.Evidence) dbpkg.StateSlash {
+	percentage := state.GetSlashPercentage()
+	sl := dbpkg.StateSlash{
+		Hash:           dbpkg.SlashHash(pub, ev.Height),
+		Validator:      pub,
+		Height:         state.Height + 1,
+		EvidenceHeight: ev.Height,
+		Type:           ev.Type,
+		Percentage:     percentage,
+		Coins:          []string{},
+	}
+
+	sss := state.GetValidatorStakes(pub)
+	staked := 0.0
+	for _, ss := range sss {
+		staked += ss.Value
+	}
+	rest := utils.ToFixed(staked*float64(percentage)/100, dbpkg.STAKE_PRECISION)
+	sort.Slice(sss, func(i, j int) bool { return sss[i].Value > sss[j].Value })
+	left := []dbpkg.StateStake{}
+	for _, ss := range sss {
+		if rest <= 0 || ss.Value > rest {
+			left = append(left, ss)
+			continue
+		}
+		sl.Coins = append(sl.Coins, ss.Coin)
+		rest = utils.ToFixed(rest-ss.Value, dbpkg.STAKE_PRECISION)
+	}
+	// the coins left are bigger than the rest, because the rest only gets smaller
+	if rest > 0 && len(left) > 0 {
+		sl.Coins = append(sl.Coins, left[len(left)-1].Coin)
+	}
+
+	for _, coin := range sl.Coins {
+		ss, _ := state.GetStake(coin)
+		state.ConfiscateStake(coin)
+		state.AddCoinHistory(coin, dbpkg.COIN_SLASHED, coinOwner(state, coin), "")
+		sl.Value = utils.ToFixed(sl.Value+ss.Value, dbpkg.STAKE_PRECISION)
+	}
+	// without coins there is not a transaction to retrieve
+	if len(sl.Coins) == 0 {
+		sl.Hash = ""
+	}
+	state.AddSlash(sl)
+	return sl
+}
@@ -0,0 +1,147 @@
+package ctrls
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/dedis/kyber"
+	"github.com/dedis/kyber/util/key"
+	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/abci/types"
+)
+
+func doubleSignEvidence(validator string, height int64) types.Evidence {
+	pub, _ := hex.DecodeString(validator)
+	return types.Evidence{
+		Type:      "duplicate/vote",
+		Validator: types.Validator{Address: utils.ValidatorAddress(pub)},
+		Height:    height,
+	}
+}
+
+func TestBeginBlockSlashesTheStakeOfTheValidator(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	app.state.SetSlashPercentage(25)
+	validator := newValidatorPubKey(1)
+	coin1, owner1 := newCoin(t, app, inflatorKp, inflatorPubHex, 5)
+	coin2, owner2 := newCoin(t, app, inflatorKp, inflatorPubHex, 2)
+	coin3, owner3 := newCoin(t, app, inflatorKp, inflatorPubHex, 1)
+	resp := stakeCoins(app, []string{coin1, coin2, coin3}, []*key.Pair{owner1, owner2, owner3}, validator)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	app.EndBlock(types.RequestEndBlock{Height: 1})
+	app.Commit()
+
+	bb := app.BeginBlock(types.RequestBeginBlock{ByzantineValidators: []types.Evidence{doubleSignEvidence(validator, 1)}})
+	hash := dbpkg.SlashHash(validator, 1)
+	assert.Equal(t, 3, len(bb.Tags))
+	assert.Equal(t, "2", string(bb.Tags[1].Value))
+	assert.Equal(t, hash, string(bb.Tags[2].Value))
+	sv, _ := app.state.GetValidator(validator)
+	assert.Equal(t, float64(6), sv.Staked)
+	_, err := app.state.GetStake(coin2)
+	assert.Equal(t, dbpkg.ERR_STAKE_DOES_NOT_EXIST(coin2), err)
+	sls := app.state.GetSlashes(validator)
+	assert.Equal(t, 1, len(sls))
+	assert.Equal(t, []string{coin2}, sls[0].Coins)
+
+	// the inflators retrieve the slashed coins like a fee
+	d := models.Delivery{}
+	d.Type = models.RETRIEVE_FEE
+	data := models.RetrieveData{}
+	data.TransactionHash = hash
+	newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
+	data.NewOwners = map[string]string{coin2: newOwnerPubHex}
+	data.Inflator = inflatorPubHex
+	dataB, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{inflatorKp.Private, newOwnerKp.Private}, dataB)
+	d.Data = data
+	b, _ := json.Marshal(d)
+	resp = app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	sc, _ := app.state.GetCoin(coin2)
+	assert.Equal(t, newOwnerPubHex, sc.Owner)
+	isLocked, _ := app.state.IsCoinLocked(coin2)
+	assert.False(t, isLocked)
+}
+
+func TestBeginBlockSlashesTheSameEvidenceOnce(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	app.state.SetSlashPercentage(100)
+	validator := newValidatorPubKey(1)
+	coin, owner := newCoin(t, app, inflatorKp, inflatorPubHex, 5)
+	resp := stakeCoins(app, []string{coin}, []*key.Pair{owner}, validator)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+
+	req := types.RequestBeginBlock{ByzantineValidators: []types.Evidence{doubleSignEvidence(validator, 1)}}
+	bb := app.BeginBlock(req)
+	assert.Equal(t, 3, len(bb.Tags))
+	bb = app.BeginBlock(req)
+	assert.Equal(t, 0, len(bb.Tags))
+	assert.Equal(t, 1, len(app.state.GetSlashes("")))
+}
+
+func TestBeginBlockSlashesAtLeastOneCoin(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	app.state.SetSlashPercentage(5)
+	validator := newValidatorPubKey(1)
+	coin, owner := newCoin(t, app, inflatorKp, inflatorPubHex, 500)
+	resp := stakeCoins(app, []string{coin}, []*key.Pair{owner}, validator)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	app.EndBlock(types.RequestEndBlock{Height: 1})
+	app.Commit()
+
+	// the single coin is more than the 5 percent, but it is taken because no smaller coin fits
+	bb := app.BeginBlock(types.RequestBeginBlock{ByzantineValidators: []types.Evidence{doubleSignEvidence(validator, 1)}})
+	assert.Equal(t, 3, len(bb.Tags))
+	assert.Equal(t, "500", string(bb.Tags[1].Value))
+	assert.Equal(t, dbpkg.SlashHash(validator, 1), string(bb.Tags[2].Value))
+	sv, _ := app.state.GetValidator(validator)
+	assert.Equal(t, float64(0), sv.Staked)
+	_, err := app.state.GetStake(coin)
+	assert.Equal(t, dbpkg.ERR_STAKE_DOES_NOT_EXIST(coin), err)
+	sls := app.state.GetSlashes(validator)
+	assert.Equal(t, 1, len(sls))
+	assert.Equal(t, []string{coin}, sls[0].Coins)
+	assert.Equal(t, float64(500), sls[0].Value)
+}
+
+func TestBeginBlockSlashesTheRestWithTheSmallestCoinLeft(t *testing.T) {
+	app := NewTMApplication()
+	inflatorKp, inflatorPubHex := utils.CreateKeyPair()
+	confs.Conf.Inflators = []string{inflatorPubHex}
+	app.state.SetSlashPercentage(20)
+	validator := newValidatorPubKey(1)
+	coin1, owner1 := newCoin(t, app, inflatorKp, inflatorPubHex, 10)
+	coin2, owner2 := newCoin(t, app, inflatorKp, inflatorPubHex, 5)
+	coin3, owner3 := newCoin(t, app, inflatorKp, inflatorPubHex, 2)
+	resp := stakeCoins(app, []string{coin1, coin2, coin3}, []*key.Pair{owner1, owner2, owner3}, validator)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+	app.EndBlock(types.RequestEndBlock{Height: 1})
+	app.Commit()
+
+	// the 2 fits in the 3.4, and the rest of 1.4 is taken with the 5, not with the 10
+	bb := app.BeginBlock(types.RequestBeginBlock{ByzantineValidators: []types.Evidence{doubleSignEvidence(validator, 1)}})
+	assert.Equal(t, "7", string(bb.Tags[1].Value))
+	sv, _ := app.state.GetValidator(validator)
+	assert.Equal(t, float64(10), sv.Staked)
+	sls := app.state.GetSlashes(validator)
+	assert.Equal(t, []string{coin3, coin2}, sls[0].Coins)
+}
+
+func TestBeginBlockIgnoresTheUnknownValidators(t *testing.T) {
+	app := NewTMApplication()
+	bb := app.BeginBlock(types.RequestBeginBlock{ByzantineValidators: []types.Evidence{doubleSignEvidence(newValidatorPubKey(1), 1)}})
+	assert.Equal(t, 0, len(bb.Tags))
+	assert.Equal(t, 0, len(app.state.GetSlashes("")))
+}
@@ -0,0 +1,74 @@
+package ctrls
+
+import (
+	"testing"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStateCoin(value float64) dbpkg.StateCoin {
+	_, ownerPubHex := utils.CreateKeyPair()
+	sc := dbpkg.StateCoin{}
+	sc.Coin = uuid.NewV4().String()
+	sc.Owner = ownerPubHex
+	sc.Value = value
+	return sc
+}
+
+func TestStateCacheDiscardsTheChanges(t *testing.T) {
+	app := NewTMApplication()
+
+	kept := newStateCoin(1)
+	app.state.AddCoin(kept)
+
+	cache := app.state.CacheWrap()
+	sc := newStateCoin(1)
+	err := cache.AddCoin(sc)
+	assert.Nil(t, err)
+	cache.DeleteCoinAndOwner(kept.Coin)
+
+	_, err = cache.GetCoin(sc.Coin)
+	assert.Nil(t, err)
+	_, err = cache.GetCoin(kept.Coin)
+	assert.NotNil(t, err)
+
+	// the cache has not been written
+	_, err = app.state.GetCoin(sc.Coin)
+	assert.NotNil(t, err)
+	_, err = app.state.GetCoin(kept.Coin)
+	assert.Nil(t, err)
+}
+
+func TestStateCacheWritesTheChanges(t *testing.T) {
+	app := NewTMApplication()
+
+	deleted := newStateCoin(2)
+	app.state.AddCoin(deleted)
+
+	cache := app.state.CacheWrap()
+	sc := newStateCoin(2)
+	cache.AddCoin(sc)
+	cache.LockCoin(sc.Coin)
+	cache.DeleteCoinAndOwner(deleted.Coin)
+
+	// the iterations merge the staged changes
+	coins := []string{}
+	cache.IterateCoinsByValue(models.MAIN_ASSET, 2, "", func(uuid string) bool {
+		coins = append(coins, uuid)
+		return true
+	})
+	assert.Equal(t, []string{sc.Coin}, coins)
+
+	cache.Write()
+	stored, err := app.state.GetCoin(sc.Coin)
+	assert.Nil(t, err)
+	assert.True(t, stored.IsLocked)
+	_, err = app.state.GetCoin(deleted.Coin)
+	assert.NotNil(t, err)
+	_, err = app.state.GetOwner(deleted.Owner)
+	assert.NotNil(t, err)
+}
@@ -69,6 +69,22 @@ func transact(t *testing.T, app *TMApplication, coins []string, fee []string, pr
 	assert.Equal(t, models.CodeTypeOK, resp.Code)
 }
 
+func transactToRecipient(t *testing.T, app *TMApplication, coins []string, fee []string, proof *dleq.Proof, privs []kyber.Scalar, recipient string) {
+	d := models.Delivery{}
+	d.Type = models.SEND
+	data := models.SendData{}
+	data.Coins = coins
+	data.Fee = fee
+	data.Proof = models.NewProof(proof)
+	data.Recipient = recipient
+	d.Data = data
+	msg, _ := json.Marshal(data)
+	d.Signature, _ = utils.MultiSignature(privs, msg)
+	b, _ := json.Marshal(d)
+	resp := app.DeliverTx(b)
+	assert.Equal(t, models.CodeTypeOK, resp.Code)
+}
+
 func receivedFee(t *testing.T, app *TMApplication, inflatorKp *key.Pair, inflatorPubHex string, coins, fee []string) {
 	msg, _ := json.Marshal(coins)
 	hash := sha256.Sum256(msg)
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/dedis/kyber"
+	"github.com/dedis/kyber/group/edwards25519"
+	"github.com/dedis/kyber/util/key"
+	"github.com/dedis/kyber/util/random"
+)
+
+// A stealth address is the hex of the view public key followed by the hex of the spend public key.
+// The sender derives from it a one-time owner for each coin, that only the holder of the view key can find
+// and only the holder of both keys can spend.
+
+var (
+	ERR_STEALTH_ADDRESS_NOT_CORRECT = errors.New("The stealth address is not correct.")
+)
+
+func CreateStealthAddress() (*key.Pair, *key.Pair, string) {
+	viewKp, viewPubHex := CreateKeyPair()
+	spendKp, spendPubHex := CreateKeyPair()
+	return viewKp, spendKp, viewPubHex + spendPubHex
+}
+
+func ParseStealthAddress(address string) (kyber.Point, kyber.Point, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	pointHexLen := 2 * suite.PointLen()
+	if len(address) != 2*pointHexLen {
+		return nil, nil, ERR_STEALTH_ADDRESS_NOT_CORRECT
+	}
+	view, err := UnmarshalPublicKey(address[:pointHexLen])
+	if err != nil {
+		return nil, nil, ERR_STEALTH_ADDRESS_NOT_CORRECT
+	}
+	spend, err := UnmarshalPublicKey(address[pointHexLen:])
+	if err != nil {
+		return nil, nil, ERR_STEALTH_ADDRESS_NOT_CORRECT
+	}
+	return view, spend, nil
+}
+
+// the shared scalar is derived from the diffie-hellman point and the coin's uuid
+func stealthScalar(dh kyber.Point, coin string) kyber.Scalar {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	dhB, _ := dh.MarshalBinary()
+	seed := append(dhB, []byte(coin)...)
+	return suite.Scalar().Pick(suite.XOF(seed))
+}
+
+// StealthOwners is used by the sender to create the one-time owners of the coins.
+// It returns the hex of the public R, that needs to be published with the transaction.
+func StealthOwners(address string, coins []string) (string, map[string]string, error) {
+	view, spend, err := ParseStealthAddress(address)
+	if err != nil {
+		return "", nil, err
+	}
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	r := suite.Scalar().Pick(random.New())
+	R := suite.Point().Mul(r, nil)
+	dh := suite.Point().Mul(r, view)
+
+	owners := map[string]string{}
+	for _, coin := range coins {
+		s := stealthScalar(dh, coin)
+		owner := suite.Point().Add(suite.Point().Mul(s, nil), spend)
+		ownerB, _ := owner.MarshalBinary()
+		owners[coin] = hex.EncodeToString(ownerB)
+	}
+	RB, _ := R.MarshalBinary()
+	return hex.EncodeToString(RB), owners, nil
+}
+
+// StealthPrivateKey is used by the receiver to check if the one-time owner of the coin belongs to the stealth address.
+// It returns the private key of the one-time owner or false when the owner is for someone else.
+func StealthPrivateKey(viewPriv, spendPriv kyber.Scalar, RHex, coin, ownerHex string) (kyber.Scalar, bool, error) {
+	R, err := UnmarshalPublicKey(RHex)
+	if err != nil {
+		return nil, false, err
+	}
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	dh := suite.Point().Mul(viewPriv, R)
+	s := stealthScalar(dh, coin)
+	priv := suite.Scalar().Add(s, spendPriv)
+	ownerB, _ := suite.Point().Mul(priv, nil).MarshalBinary()
+	if hex.EncodeToString(ownerB) != ownerHex {
+		return nil, false, nil
+	}
+	return priv, true, nil
+}
@@ -7,9 +7,11 @@ import (
 
 	"github.com/dedis/kyber"
 
+	"github.com/dedis/kyber/encrypt/ecies"
 	"github.com/dedis/kyber/group/edwards25519"
 	"github.com/dedis/kyber/sign/schnorr"
 	"github.com/dedis/kyber/util/key"
+	crypto "github.com/tendermint/go-crypto"
 )
 
 func CreateKeyPair() (*key.Pair, string) {
@@ -27,7 +29,10 @@ func UnmarshalPublicKey(pub string) (kyber.Point, error) {
 	if err != nil {
 		return nil, err
 	}
-	p.UnmarshalBinary(b)
+	err = p.UnmarshalBinary(b)
+	if err != nil {
+		return nil, err
+	}
 	return p, nil
 }
 
@@ -94,6 +99,28 @@ func Verify(pubHex string, sig []byte, msg []byte) (bool, error) {
 	return true, nil
 }
 
+func Encrypt(pubHex string, msg []byte) (string, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	pub, err := UnmarshalPublicKey(pubHex)
+	if err != nil {
+		return "", err
+	}
+	c, err := ecies.Encrypt(suite, pub, msg, nil)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(c), nil
+}
+
+func Decrypt(priv kyber.Scalar, cipherHex string) ([]byte, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	c, err := hex.DecodeString(cipherHex)
+	if err != nil {
+		return nil, errors.New("The cipher is not hex: " + err.Error())
+	}
+	return ecies.Decrypt(suite, priv, c, nil)
+}
+
 func round(num float64) int {
 	return int(num + math.Copysign(0.5, num))
 }
@@ -102,3 +129,10 @@ func ToFixed(num float64, precision int) float64 {
 	output := math.Pow(10, float64(precision))
 	return float64(round(num*output)) / output
 }
+
+// ValidatorAddress returns the address of the validator's ed25519 public key, that tendermint uses in the evidence
+func ValidatorAddress(pub []byte) []byte {
+	key := crypto.PubKeyEd25519{}
+	copy(key[:], pub)
+	return key.Address()
+}
@@ -0,0 +1,84 @@
+package validations
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+)
+
+var assetNameRegexp = regexp.MustCompile("^[a-z0-9_-]{1,32}$")
+
+var (
+	ERR_ASSET_NAME_NOT_CORRECT = func(name string) error {
+		return errors.New("The name of the asset " + name + " must be from 1 to 32 lowercase letters, digits, '_' or '-'.")
+	}
+	ERR_ASSET_ADDED_TWICE = func(name string) error {
+		return errors.New("The asset " + name + " has been added twice.")
+	}
+	ERR_ASSET_INFLATORS_EMPTY = func(name string) error {
+		return errors.New("The inflators of the asset " + name + " are empty.")
+	}
+	ERR_COINS_OF_DIFFERENT_ASSETS = errors.New("The coins are of different assets.")
+)
+
+// ValidateAsset validates an asset of the genesis
+func ValidateAsset(s *dbpkg.State, a models.Asset) (uint32, error) {
+	if !assetNameRegexp.MatchString(a.Name) {
+		return models.CodeTypeAssetNotCorrect, ERR_ASSET_NAME_NOT_CORRECT(a.Name)
+	}
+	if _, err := s.GetAsset(a.Name); err == nil {
+		return models.CodeTypeAssetNotCorrect, ERR_ASSET_ADDED_TWICE(a.Name)
+	}
+	if len(a.Inflators) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_ASSET_INFLATORS_EMPTY(a.Name)
+	}
+	code, err := validatePublicKeysFormat(a.Inflators...)
+	if err != nil {
+		return code, err
+	}
+	if len(a.Denominations) > 0 {
+		return ValidateDenominations(a.Denominations)
+	}
+	return models.CodeTypeOK, nil
+}
+
+// validateInflatorOfAsset checks that the inflator can inflate the asset,
+// the inflators of the main currency are the inflators of the configuration that are active on the block
+func validateInflatorOfAsset(s *dbpkg.State, asset, inflator string) (uint32, error) {
+	inflators := confs.Conf.GetInflators(s.Height + 1)
+	if asset != models.MAIN_ASSET {
+		a, err := s.GetAsset(asset)
+		if err != nil {
+			return models.CodeTypeAssetNotCorrect, err
+		}
+		inflators = a.Inflators
+	}
+	for _, v := range inflators {
+		if v == inflator {
+			return models.CodeTypeOK, nil
+		}
+	}
+	return models.CodeTypeInflatorNotInList, ERR_INFLATOR_NOT_IN_LIST
+}
+
+// coinsAsset returns the asset of the coins and it fails when the coins are of different assets.
+// The coins that do not exist are skipped, so their own validation reports them.
+func coinsAsset(s *dbpkg.State, coins []string) (string, uint32, error) {
+	asset := models.MAIN_ASSET
+	found := false
+	for _, coin := range coins {
+		sc, err := s.GetCoin(coin)
+		if err != nil {
+			continue
+		}
+		if found && sc.Asset != asset {
+			return "", models.CodeTypeAssetNotCorrect, ERR_COINS_OF_DIFFERENT_ASSETS
+		}
+		asset = sc.Asset
+		found = true
+	}
+	return asset, models.CodeTypeOK, nil
+}
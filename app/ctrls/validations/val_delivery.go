@@ -0,0 +1,26 @@
+package validations
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+)
+
+var (
+	ERR_JSON_DELIVERIES_NOT_ACCEPTED = func(height int64) error {
+		return errors.New("The json deliveries are not accepted after the height " + strconv.FormatInt(height, 10) + ", use the binary deliveries.")
+	}
+)
+
+// ValidateDeliveryVersion rejects the json deliveries after the last height that accepts them,
+// because their signature is not for a chain and it can be replayed on another chain.
+// The last height is in the state from the genesis, so every validator rejects the same deliveries.
+func ValidateDeliveryVersion(s *dbpkg.State, version models.DeliveryVersion) (uint32, error) {
+	last := s.GetLastJSONHeight()
+	if version == models.DELIVERY_VERSION_JSON && last > 0 && s.Height+1 > last {
+		return models.CodeTypeEncodingError, ERR_JSON_DELIVERIES_NOT_ACCEPTED(last)
+	}
+	return models.CodeTypeOK, nil
+}
@@ -0,0 +1,85 @@
+package validations
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+)
+
+var (
+	ERR_DEMURRAGE_NEGATIVE         = errors.New("The demurrage can not be negative.")
+	ERR_DEMURRAGE_OVER_PARTS       = errors.New(fmt.Sprint("The demurrage can not be over ", models.DEMURRAGE_PARTS, "."))
+	ERR_FEE_NOT_BASED_ON_DEMURRAGE = func(missing float64) error {
+		return errors.New(fmt.Sprint("The fee is not based on the demurrage, it is missing", missing, "."))
+	}
+)
+
+// demurrageFee returns the fee of holding the coins, with the latest demurrage of their asset
+func demurrageFee(s *dbpkg.State, asset string, coins []string) float64 {
+	tax := s.GetAssetTax(asset)
+	if tax.Demurrage == 0 {
+		return 0
+	}
+	denominations := s.GetAssetDenominations(asset)
+	fee := 0.0
+	for _, coin := range coins {
+		sc, err := s.GetCoin(coin)
+		if err != nil {
+			continue
+		}
+		fee += tax.GetDemurrageFee(sc.Value, s.DemurrageBlocks(sc), denominations)
+	}
+	return utils.ToFixed(fee, models.DenominationsPrecision(denominations))
+}
+
+// validateDemurrageFee validates the fee of the sum and the division, that pays the demurrage
+// of the coins and of the fee, and it returns the owners of the fee that need to sign
+func validateDemurrageFee(s *dbpkg.State, coins, fee []string) ([]string, uint32, error) {
+	checkCoins := map[string]int{}
+	for _, v := range coins {
+		checkCoins[v] = 0
+	}
+	checkFees := map[string]int{}
+	for _, v := range fee {
+		if _, ok := checkFees[v]; ok {
+			return nil, models.CodeTypeCoinDuplicate, ERR_COIN_FROM_FEE_ADDED_TWICE(v)
+		}
+		checkFees[v] = 0
+		if _, ok := checkCoins[v]; ok {
+			return nil, models.CodeTypeCoinDuplicate, ERR_COIN_ADDED_ON_BOTH_COINS_AND_FEE(v)
+		}
+	}
+	code, err := validateCoinsFormat(fee...)
+	if err != nil {
+		return nil, code, err
+	}
+
+	allCoins := append(append([]string{}, coins...), fee...)
+	asset, code, err := coinsAsset(s, allCoins)
+	if err != nil {
+		return nil, code, err
+	}
+	owners := []string{}
+	sumFee := 0.0
+	for _, v := range fee {
+		sc, err := s.GetCoin(v)
+		if err != nil {
+			return nil, models.CodeTypeCoinNotFound, ERR_COIN_FROM_FEE_DOES_NOT_EXISTS(v)
+		}
+		if sc.IsLocked {
+			return nil, models.CodeTypeCoinLocked, ERR_COIN_IS_LOCKED(v)
+		}
+		sumFee += sc.Value
+		owners = append(owners, sc.Owner)
+	}
+
+	required := demurrageFee(s, asset, allCoins)
+	if required > sumFee {
+		sub := utils.ToFixed(required-sumFee, models.DenominationsPrecision(s.GetAssetDenominations(asset)))
+		return nil, models.CodeTypeFeeInsufficient, ERR_FEE_NOT_BASED_ON_DEMURRAGE(sub)
+	}
+	return owners, models.CodeTypeOK, nil
+}
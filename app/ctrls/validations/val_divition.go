@@ -1,8 +1,8 @@
 package validations
 
 import (
-	"encoding/json"
 	"errors"
+	"sort"
 
 	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
@@ -29,45 +29,60 @@ var (
 	}
 )
 
-func ValidateDivition(s *dbpkg.State, dd models.DivitionData, sig []byte) (uint32, error) {
+func ValidateDivition(s *dbpkg.State, dd models.DivitionData, msg, sig []byte) (uint32, error) {
 	if len(dd.Coin) == 0 {
-		return models.CodeTypeUnauthorized, ERR_COIN_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_COIN_EMPTY
 	}
 
 	if len(dd.NewCoins) == 0 {
-		return models.CodeTypeUnauthorized, ERR_NEW_COINS_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_NEW_COINS_EMPTY
 	}
+	newCoins := []string{}
+	for k := range dd.NewCoins {
+		newCoins = append(newCoins, k)
+	}
+	sort.Strings(newCoins)
+	code, err := validateCoinsFormat(append([]string{dd.Coin}, newCoins...)...)
+	if err != nil {
+		return code, err
+	}
+	// the new coins have the asset of the coin
+	asset, _, _ := coinsAsset(s, []string{dd.Coin})
+	denominations := s.GetAssetDenominations(asset)
 	checkOwners := map[string]string{}
 	sum := 0.0
 	ownerPubs := []string{}
 	for k, coin := range dd.NewCoins {
 		if len(coin.Owner) == 0 {
-			return models.CodeTypeUnauthorized, ERR_NEW_COIN_OWNER_EMPTY(k)
+			return models.CodeTypeFieldEmpty, ERR_NEW_COIN_OWNER_EMPTY(k)
+		}
+		if code, err := validatePublicKeysFormat(coin.Owner); err != nil {
+			return code, err
 		}
 
 		isFound := false
-		for _, v := range models.CONSTANT_VALUES {
+		for _, v := range denominations {
 			if v == coin.Value {
 				isFound = true
 				break
 			}
 		}
 		if !isFound {
-			return models.CodeTypeUnauthorized, ERR_NEW_COIN_NON_CONSTANT_VALUE(k)
+			return models.CodeTypeValueNotConstant, ERR_NEW_COIN_NON_CONSTANT_VALUE(k)
 		}
 		_, ok := checkOwners[coin.Owner]
 		if ok {
-			return models.CodeTypeUnauthorized, ERR_NEW_COINS_EQUAL_OWNER
+			return models.CodeTypeCoinDuplicate, ERR_NEW_COINS_EQUAL_OWNER
 		} else {
 			checkOwners[coin.Owner] = coin.Owner
 		}
 		_, err := s.GetCoin(k)
 		if err == nil {
-			return models.CodeTypeUnauthorized, ERR_COIN_FROM_NEW_COINS_EXISTS_ALREADY(k)
+			return models.CodeTypeCoinExists, ERR_COIN_FROM_NEW_COINS_EXISTS_ALREADY(k)
 		}
 		_, err = s.GetOwner(coin.Owner)
 		if err == nil {
-			return models.CodeTypeUnauthorized, ERR_OWNER_FROM_NEW_COINS_EXISTS_ALREADY(coin.Owner)
+			return models.CodeTypeOwnerExists, ERR_OWNER_FROM_NEW_COINS_EXISTS_ALREADY(coin.Owner)
 		}
 		sum += coin.Value
 		ownerPubs = append(ownerPubs, coin.Owner)
@@ -75,28 +90,33 @@ func ValidateDivition(s *dbpkg.State, dd models.DivitionData, sig []byte) (uint3
 
 	sc, err := s.GetCoin(dd.Coin)
 	if err != nil {
-		return models.CodeTypeUnauthorized, ERR_COIN_DOES_NOT_EXISTS
+		return models.CodeTypeCoinNotFound, ERR_COIN_DOES_NOT_EXISTS
 	}
 
 	if sum != sc.Value {
-		return models.CodeTypeUnauthorized, ERR_NEW_COINS_IS_NOT_EQUAL_TO_THE_COIN
+		return models.CodeTypeValueNotConstant, ERR_NEW_COINS_IS_NOT_EQUAL_TO_THE_COIN
 	}
 	ownerPubs = append(ownerPubs, sc.Owner)
-	msg, _ := json.Marshal(dd)
+
+	feeOwners, code, err := validateDemurrageFee(s, []string{dd.Coin}, dd.Fee)
+	if err != nil {
+		return code, err
+	}
+	ownerPubs = append(ownerPubs, feeOwners...)
 	isValid, err := utils.MultiVerify(ownerPubs, sig, msg)
 	if err != nil {
-		return models.CodeTypeUnauthorized, err
+		return models.CodeTypeBadData, err
 	}
 	if !isValid {
-		return models.CodeTypeUnauthorized, ERR_SIGNATURE_NOT_VALID
+		return models.CodeTypeSignatureNotValid, ERR_SIGNATURE_NOT_VALID
 	}
 
 	isLocked, err := s.IsCoinLocked(dd.Coin)
 	if err != nil {
-		return models.CodeTypeUnauthorized, err
+		return models.CodeTypeServerError, err
 	}
 	if isLocked {
-		return models.CodeTypeUnauthorized, ERR_COIN_IS_LOCKED(dd.Coin)
+		return models.CodeTypeCoinLocked, ERR_COIN_IS_LOCKED(dd.Coin)
 	}
 
 	return models.CodeTypeOK, nil
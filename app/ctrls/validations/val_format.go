@@ -0,0 +1,76 @@
+package validations
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	uuid "github.com/satori/go.uuid"
+)
+
+// the maximum number of coins in the lists of a delivery
+const MAX_COINS_IN_DELIVERY = 1000
+
+var (
+	ERR_TOO_MANY_COINS = errors.New(fmt.Sprint("The delivery can not have more than ", MAX_COINS_IN_DELIVERY, " coins."))
+	ERR_COIN_NOT_UUID  = func(coin string) error {
+		return errors.New("The coin " + coin + " is not a uuid.")
+	}
+	ERR_PUBLIC_KEY_NOT_CORRECT = func(pub string) error {
+		return errors.New("The public key " + pub + " is not a correct point in lowercase hex.")
+	}
+)
+
+// validateCoinsFormat checks the number of the coins and that each coin is a uuid in its canonical form,
+// so the same coin can not be written in two ways
+func validateCoinsFormat(coins ...string) (uint32, error) {
+	if len(coins) > MAX_COINS_IN_DELIVERY {
+		return models.CodeTypeBadData, ERR_TOO_MANY_COINS
+	}
+	for _, coin := range coins {
+		u, err := uuid.FromString(coin)
+		if err != nil || u.String() != coin {
+			return models.CodeTypeBadData, ERR_COIN_NOT_UUID(coin)
+		}
+	}
+	return models.CodeTypeOK, nil
+}
+
+// validatePublicKeysFormat checks that each public key is the hex of a point in its canonical form,
+// so the same owner can not be written in two ways
+func validatePublicKeysFormat(pubs ...string) (uint32, error) {
+	for _, pub := range pubs {
+		p, err := utils.UnmarshalPublicKey(pub)
+		if err != nil {
+			return models.CodeTypeBadData, ERR_PUBLIC_KEY_NOT_CORRECT(pub)
+		}
+		b, err := p.MarshalBinary()
+		if err != nil || hex.EncodeToString(b) != pub {
+			return models.CodeTypeBadData, ERR_PUBLIC_KEY_NOT_CORRECT(pub)
+		}
+	}
+	return models.CodeTypeOK, nil
+}
+
+// validateNewOwnersFormat checks the coins and the public keys of the map, in the order of the coins
+func validateNewOwnersFormat(newOwners map[string]string) (uint32, error) {
+	coins := []string{}
+	for coin := range newOwners {
+		coins = append(coins, coin)
+	}
+	sort.Strings(coins)
+	code, err := validateCoinsFormat(coins...)
+	if err != nil {
+		return code, err
+	}
+	for _, coin := range coins {
+		code, err := validatePublicKeysFormat(newOwners[coin])
+		if err != nil {
+			return code, err
+		}
+	}
+	return models.CodeTypeOK, nil
+}
@@ -0,0 +1,65 @@
+package validations
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+)
+
+var (
+	ERR_DENOMINATIONS_EMPTY       = errors.New("The denominations are empty.")
+	ERR_DENOMINATION_NOT_POSITIVE = func(value float64) error {
+		return errors.New(fmt.Sprint("The denomination ", value, " is not positive."))
+	}
+	ERR_DENOMINATION_ADDED_TWICE = func(value float64) error {
+		return errors.New(fmt.Sprint("The denomination ", value, " has been added twice."))
+	}
+)
+
+// ValidateDenominations validates the denominations of the genesis
+func ValidateDenominations(denominations []float64) (uint32, error) {
+	if len(denominations) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_DENOMINATIONS_EMPTY
+	}
+	checkValues := map[float64]int{}
+	for _, v := range denominations {
+		if v <= 0 {
+			return models.CodeTypeValueNotConstant, ERR_DENOMINATION_NOT_POSITIVE(v)
+		}
+		if _, ok := checkValues[v]; ok {
+			return models.CodeTypeCoinDuplicate, ERR_DENOMINATION_ADDED_TWICE(v)
+		}
+		checkValues[v] = 0
+	}
+	return models.CodeTypeOK, nil
+}
+
+// ValidateGenesisCoin validates the format and the value of a coin of the genesis,
+// the state checks that the coin and the owner do not exist already
+func ValidateGenesisCoin(s *dbpkg.State, gc models.GenesisCoin) (uint32, error) {
+	if len(gc.Coin) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_COIN_EMPTY
+	}
+	if len(gc.Owner) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_OWNER_EMPTY
+	}
+	code, err := validateCoinsFormat(gc.Coin)
+	if err != nil {
+		return code, err
+	}
+	code, err = validatePublicKeysFormat(gc.Owner)
+	if err != nil {
+		return code, err
+	}
+	if gc.Asset != models.MAIN_ASSET {
+		if _, err := s.GetAsset(gc.Asset); err != nil {
+			return models.CodeTypeAssetNotCorrect, err
+		}
+	}
+	if !models.IsDenomination(s.GetAssetDenominations(gc.Asset), gc.Value) {
+		return models.CodeTypeValueNotConstant, ERR_VALUE_NOT_IN_LIST
+	}
+	return models.CodeTypeOK, nil
+}
@@ -1,12 +1,11 @@
 package validations
 
 import (
-	"encoding/json"
 	"errors"
 
 	"github.com/dedis/kyber/group/edwards25519"
 	"github.com/dedis/kyber/sign/schnorr"
-	"github.com/mragiadakos/tendermoney/app/confs"
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
 )
@@ -21,57 +20,58 @@ var (
 	ERR_SIGNATURE_NOT_VALID  = errors.New("The public keys do not validate the signature.")
 )
 
-func ValidateInflation(id models.InflationData, sig []byte) (uint32, error) {
+func ValidateInflation(s *dbpkg.State, id models.InflationData, msg, sig []byte) (uint32, error) {
 	if len(id.Coin) == 0 {
-		return models.CodeTypeUnauthorized, ERR_COIN_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_COIN_EMPTY
 	}
 	if len(sig) == 0 {
-		return models.CodeTypeUnauthorized, ERR_SIGNATURE_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_SIGNATURE_EMPTY
 	}
 	if len(id.Owner) == 0 {
-		return models.CodeTypeUnauthorized, ERR_OWNER_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_OWNER_EMPTY
 	}
 
 	if len(id.Inflator) == 0 {
-		return models.CodeTypeUnauthorized, ERR_INFLATOR_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_INFLATOR_EMPTY
 	}
-
-	inList := false
-	for _, v := range confs.Conf.Inflators {
-		if v == id.Inflator {
-			inList = true
-			break
-		}
+	code, err := validateCoinsFormat(id.Coin)
+	if err != nil {
+		return code, err
 	}
-	if !inList {
-		return models.CodeTypeUnauthorized, ERR_INFLATOR_NOT_IN_LIST
+	code, err = validatePublicKeysFormat(id.Owner, id.Inflator)
+	if err != nil {
+		return code, err
+	}
+
+	code, err = validateInflatorOfAsset(s, id.Asset, id.Inflator)
+	if err != nil {
+		return code, err
 	}
 
-	inList = false
-	for _, v := range models.CONSTANT_VALUES {
+	inList := false
+	for _, v := range s.GetAssetDenominations(id.Asset) {
 		if v == id.Value {
 			inList = true
 			break
 		}
 	}
 	if !inList {
-		return models.CodeTypeUnauthorized, ERR_VALUE_NOT_IN_LIST
+		return models.CodeTypeValueNotConstant, ERR_VALUE_NOT_IN_LIST
 	}
 
 	suite := edwards25519.NewBlakeSHA256Ed25519()
 	pubOwner, err := utils.UnmarshalPublicKey(id.Owner)
 	if err != nil {
-		return models.CodeTypeUnauthorized, err
+		return models.CodeTypeBadData, err
 	}
 	pubInflator, err := utils.UnmarshalPublicKey(id.Inflator)
 	if err != nil {
-		return models.CodeTypeUnauthorized, err
+		return models.CodeTypeBadData, err
 	}
 	onePublic := suite.Point().Add(pubInflator, pubOwner)
-	msg, _ := json.Marshal(id)
 	err = schnorr.Verify(suite, onePublic, msg, sig)
 	if err != nil {
-		return models.CodeTypeUnauthorized, ERR_SIGNATURE_NOT_VALID
+		return models.CodeTypeSignatureNotValid, ERR_SIGNATURE_NOT_VALID
 	}
 	return models.CodeTypeOK, nil
 }
@@ -1,7 +1,7 @@
 package validations
 
 import (
-	"encoding/json"
+	"encoding/hex"
 	"errors"
 
 	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
@@ -23,20 +23,38 @@ var (
 	ERR_PROOF_VERIFICATION_IS_NOT_CORRECT = errors.New("The proof's verification is not correct.")
 	ERR_PROOF_VERIFICATION_IS_NOT_VALID   = errors.New("The proof's verification is not valid.")
 	ERR_TRANSACTION_HAS_BEEN_RECEIVED     = errors.New("The transaction has been received.")
-	ERR_NEW_OWNERS_NOT_EQUAL_TO_COINS     = errors.New("The number of new owners is not equal to the coins.")
+	ERR_STEALTH_TRANSACTION_NOT_RECEIVED  = errors.New("The coins of the stealth transaction belong to its stealth owners, they can not be received.")
+	ERR_NEW_OWNERS_EMPTY                  = errors.New("The new owners are empty.")
+	ERR_COIN_HAS_BEEN_RECEIVED            = func(uuid string) error {
+		return errors.New("The coin " + uuid + " from the transaction has been received.")
+	}
+	ERR_PARTIAL_RECEIVE_WITHOUT_RECIPIENT = errors.New("The transaction does not have a recipient, all its coins need to be received together.")
+	ERR_RECIPIENT_SIGNATURE_EMPTY         = errors.New("The signature of the recipient is empty.")
+	ERR_RECIPIENT_SIGNATURE_NOT_HEX       = errors.New("The signature of the recipient is not hex.")
+	ERR_RECIPIENT_SIGNATURE_NOT_VALID     = errors.New("The recipient's public key does not validate the signature of the recipient.")
+	ERR_RECIPIENT_SIGNATURE_NOT_ALLOWED   = errors.New("The transaction does not have a recipient, the signature of the recipient is not allowed.")
 )
 
-func ValidateReceive(state *dbpkg.State, rd models.ReceiveData, sig []byte) (uint32, error) {
+func ValidateReceive(state *dbpkg.State, rd models.ReceiveData, msg, sig []byte) (uint32, error) {
 	if len(rd.TransactionHash) == 0 {
-		return models.CodeTypeUnauthorized, ERR_TRANSACTION_HASH_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_TRANSACTION_HASH_EMPTY
 	}
 	tr, err := state.GetTransaction(rd.TransactionHash)
 	if err != nil {
-		return models.CodeTypeUnauthorized, ERR_TRANSACTION_HASH_DOES_NOT_EXIST
+		return models.CodeTypeTransactionNotFound, ERR_TRANSACTION_HASH_DOES_NOT_EXIST
+	}
+	// the stealth send sets the owners of the coins, and it does not have a proof to verify
+	if tr.IsStealth() {
+		return models.CodeTypeTransactionReceived, ERR_STEALTH_TRANSACTION_NOT_RECEIVED
 	}
 
-	if len(rd.NewOwners) != len(tr.Coins) {
-		return models.CodeTypeUnauthorized, ERR_NEW_OWNERS_NOT_EQUAL_TO_COINS
+	// the receiver can claim only a part of the coins each time
+	if len(rd.NewOwners) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_NEW_OWNERS_EMPTY
+	}
+	code, err := validateNewOwnersFormat(rd.NewOwners)
+	if err != nil {
+		return code, err
 	}
 	for coin, owner := range rd.NewOwners {
 		isFoundCoin := false
@@ -47,14 +65,19 @@ func ValidateReceive(state *dbpkg.State, rd models.ReceiveData, sig []byte) (uin
 			}
 		}
 		if !isFoundCoin {
-			return models.CodeTypeUnauthorized, ERR_COIN_IS_NOT_IN_TRANSACTION(coin)
+			return models.CodeTypeCoinNotInTransaction, ERR_COIN_IS_NOT_IN_TRANSACTION(coin)
 		}
 
 		_, err := state.GetOwner(owner)
 		if err == nil {
-			return models.CodeTypeUnauthorized, ERR_OWNER_FROM_COINS_EXISTS_ALREADY(owner, coin)
+			return models.CodeTypeOwnerExists, ERR_OWNER_FROM_COINS_EXISTS_ALREADY(owner, coin)
 		}
 	}
+	// the secret of a transaction without a recipient is public after the first receive,
+	// so anyone could claim the rest of its coins
+	if len(tr.Recipient) == 0 && len(rd.NewOwners) != len(tr.Coins) {
+		return models.CodeTypeCoinNotInTransaction, ERR_PARTIAL_RECEIVE_WITHOUT_RECIPIENT
+	}
 
 	proof, err := tr.Proof.GetProof()
 	if err != nil {
@@ -63,29 +86,64 @@ func ValidateReceive(state *dbpkg.State, rd models.ReceiveData, sig []byte) (uin
 
 	pvp, err := rd.ProofVerification.GetProof()
 	if err != nil {
-		return models.CodeTypeUnauthorized, ERR_PROOF_VERIFICATION_IS_NOT_CORRECT
+		return models.CodeTypeProofNotValid, ERR_PROOF_VERIFICATION_IS_NOT_CORRECT
 	}
 
 	suite := edwards25519.NewBlakeSHA256Ed25519()
 	err = proof.Verify(suite, pvp.G, pvp.H, pvp.XG, pvp.XH)
 	if err != nil {
-		return models.CodeTypeUnauthorized, ERR_PROOF_VERIFICATION_IS_NOT_VALID
+		return models.CodeTypeProofNotValid, ERR_PROOF_VERIFICATION_IS_NOT_VALID
 	}
 	owners := []string{}
 	for _, v := range rd.NewOwners {
 		owners = append(owners, v)
 	}
-	msg, _ := json.Marshal(rd)
 	isValid, err := utils.MultiVerify(owners, sig, msg)
 	if err != nil {
-		return models.CodeTypeEncodingError, err
+		return models.CodeTypeBadData, err
 	}
 	if !isValid {
-		return models.CodeTypeUnauthorized, ERR_SIGNATURE_NOT_VALID
+		return models.CodeTypeSignatureNotValid, ERR_SIGNATURE_NOT_VALID
+	}
+	code, err = validateRecipientSignature(tr.Recipient, rd.RecipientSignature, msg)
+	if err != nil {
+		return code, err
 	}
 
 	if tr.IsCoinsReceived {
-		return models.CodeTypeUnauthorized, ERR_TRANSACTION_HAS_BEEN_RECEIVED
+		return models.CodeTypeTransactionReceived, ERR_TRANSACTION_HAS_BEEN_RECEIVED
+	}
+	for coin := range rd.NewOwners {
+		if tr.IsCoinReceived(coin) {
+			return models.CodeTypeTransactionReceived, ERR_COIN_HAS_BEEN_RECEIVED(coin)
+		}
+	}
+	return models.CodeTypeOK, nil
+}
+
+// validateRecipientSignature verifies the recipient's signature on its own,
+// because the new owners are chosen by the receiver, a new owner's key could cancel out the recipient's key
+// from the sum of the keys
+func validateRecipientSignature(recipient, recipientSig string, msg []byte) (uint32, error) {
+	if len(recipient) == 0 {
+		if len(recipientSig) > 0 {
+			return models.CodeTypeBadData, ERR_RECIPIENT_SIGNATURE_NOT_ALLOWED
+		}
+		return models.CodeTypeOK, nil
+	}
+	if len(recipientSig) == 0 {
+		return models.CodeTypeSignatureNotValid, ERR_RECIPIENT_SIGNATURE_EMPTY
+	}
+	sigB, err := hex.DecodeString(recipientSig)
+	if err != nil {
+		return models.CodeTypeSignatureNotValid, ERR_RECIPIENT_SIGNATURE_NOT_HEX
+	}
+	isValid, err := utils.Verify(recipient, sigB, msg)
+	if err != nil {
+		return models.CodeTypeServerError, errors.New("A validator accepted an incorrect recipient for the transaction: " + err.Error())
+	}
+	if !isValid {
+		return models.CodeTypeSignatureNotValid, ERR_RECIPIENT_SIGNATURE_NOT_VALID
 	}
 	return models.CodeTypeOK, nil
 }
@@ -1,12 +1,10 @@
 package validations
 
 import (
-	"encoding/json"
 	"errors"
 
 	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
 
-	"github.com/mragiadakos/tendermoney/app/confs"
 	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 )
@@ -20,37 +18,39 @@ var (
 	ERR_TRANSACTION_HAS_BEEN_RETRIEVED = errors.New("The fees from the transaction has already been received.")
 )
 
-func ValidateRetrieve(state *dbpkg.State, rd models.RetrieveData, sig []byte) (uint32, error) {
+func ValidateRetrieve(state *dbpkg.State, rd models.RetrieveData, msg, sig []byte) (uint32, error) {
 	tr, err := state.GetTransaction(rd.TransactionHash)
 	if err != nil {
-		return models.CodeTypeUnauthorized, ERR_TRANSACTION_HASH_DOES_NOT_EXIST
+		return models.CodeTypeTransactionNotFound, ERR_TRANSACTION_HASH_DOES_NOT_EXIST
 	}
 	if len(tr.Fee) == 0 {
-		return models.CodeTypeUnauthorized, ERR_TRANSACTION_DOES_NOT_HAVE_FEE
+		return models.CodeTypeCoinNotInTransaction, ERR_TRANSACTION_DOES_NOT_HAVE_FEE
 	}
 	if len(rd.NewOwners) != len(tr.Fee) {
-		return models.CodeTypeUnauthorized, ERR_NEW_OWNERS_NOT_EQUAL_TO_FEES
+		return models.CodeTypeCoinNotInTransaction, ERR_NEW_OWNERS_NOT_EQUAL_TO_FEES
 	}
 	if len(rd.Inflator) == 0 {
-		return models.CodeTypeUnauthorized, ERR_INFLATOR_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_INFLATOR_EMPTY
 	}
 
-	inList := false
-	for _, v := range confs.Conf.Inflators {
-		if v == rd.Inflator {
-			inList = true
-			break
-		}
+	asset, code, err := coinsAsset(state, tr.Fee)
+	if err != nil {
+		return code, err
 	}
-	if !inList {
-		return models.CodeTypeUnauthorized, ERR_INFLATOR_NOT_IN_LIST
+	code, err = validateInflatorOfAsset(state, asset, rd.Inflator)
+	if err != nil {
+		return code, err
+	}
+	code, err = validateNewOwnersFormat(rd.NewOwners)
+	if err != nil {
+		return code, err
 	}
 
 	allPubs := []string{rd.Inflator}
 	for coin, owner := range rd.NewOwners {
 		_, err := state.GetOwner(owner)
 		if err == nil {
-			return models.CodeTypeUnauthorized, ERR_OWNER_FROM_COINS_EXISTS_ALREADY(owner, coin)
+			return models.CodeTypeOwnerExists, ERR_OWNER_FROM_COINS_EXISTS_ALREADY(owner, coin)
 		}
 
 		isFoundCoin := false
@@ -61,22 +61,21 @@ func ValidateRetrieve(state *dbpkg.State, rd models.RetrieveData, sig []byte) (u
 			}
 		}
 		if !isFoundCoin {
-			return models.CodeTypeUnauthorized, ERR_COIN_IS_NOT_IN_FEE_TRANSACTION(coin)
+			return models.CodeTypeCoinNotInTransaction, ERR_COIN_IS_NOT_IN_FEE_TRANSACTION(coin)
 		}
 		allPubs = append(allPubs, owner)
 	}
-	msg, _ := json.Marshal(rd)
 	isValid, err := utils.MultiVerify(allPubs, sig, msg)
 	if err != nil {
-		return models.CodeTypeUnauthorized, err
+		return models.CodeTypeBadData, err
 	}
 
 	if !isValid {
-		return models.CodeTypeUnauthorized, ERR_SIGNATURE_NOT_VALID
+		return models.CodeTypeSignatureNotValid, ERR_SIGNATURE_NOT_VALID
 	}
 
 	if tr.IsFeeReceived {
-		return models.CodeTypeUnauthorized, ERR_TRANSACTION_HAS_BEEN_RETRIEVED
+		return models.CodeTypeTransactionReceived, ERR_TRANSACTION_HAS_BEEN_RETRIEVED
 	}
 	return models.CodeTypeOK, nil
 }
@@ -1,7 +1,7 @@
 package validations
 
 import (
-	"encoding/json"
+	"encoding/hex"
 	"errors"
 	"fmt"
 
@@ -35,27 +35,76 @@ var (
 	ERR_COIN_IS_LOCKED    = func(uuid string) error {
 		return errors.New("The coin " + uuid + " is locked.")
 	}
+	ERR_MEMO_TOO_LONG                     = errors.New(fmt.Sprint("The memo can not be over ", models.MEMO_MAX_LENGTH, " bytes."))
+	ERR_ENCRYPTED_MEMO_TOO_LONG           = errors.New(fmt.Sprint("The encrypted memo can not be over ", models.ENCRYPTED_MEMO_MAX_LENGTH, " hex characters."))
+	ERR_ENCRYPTED_MEMO_NOT_HEX            = errors.New("The encrypted memo is not correct hex.")
+	ERR_ENCRYPTED_SECRET_TOO_LONG         = errors.New(fmt.Sprint("The encrypted secret can not be over ", models.ENCRYPTED_SECRET_MAX_LENGTH, " hex characters."))
+	ERR_ENCRYPTED_SECRET_NOT_HEX          = errors.New("The encrypted secret is not correct hex.")
+	ERR_RECIPIENT_NOT_CORRECT             = errors.New("The recipient's public key is not correct.")
+	ERR_STEALTH_R_NOT_CORRECT             = errors.New("The stealth's R is not correct.")
+	ERR_STEALTH_OWNERS_NOT_EQUAL_TO_COINS = errors.New("The number of stealth owners is not equal to the coins.")
+	ERR_STEALTH_OWNER_MISSING             = func(uuid string) error {
+		return errors.New("The coin " + uuid + " does not have a stealth owner.")
+	}
+	ERR_STEALTH_OWNERS_EQUAL = errors.New("The stealth owners are equal.")
+	ERR_STEALTH_WITH_RECEIVE = errors.New("The stealth send can not have a recipient, an encrypted secret or a proof, its coins are not received.")
 )
 
-func ValidateSend(s *dbpkg.State, sd models.SendData, sig []byte) (uint32, error) {
+func ValidateSend(s *dbpkg.State, sd models.SendData, msg, sig []byte) (uint32, error) {
 
 	if len(sd.Coins) == 0 {
-		return models.CodeTypeUnauthorized, ERR_COINS_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_COINS_EMPTY
 	}
 
 	checkCoins := map[string]int{}
 	for _, v := range sd.Coins {
 		_, ok := checkCoins[v]
 		if ok {
-			return models.CodeTypeUnauthorized, ERR_COIN_FROM_COINS_ADDED_TWICE(v)
+			return models.CodeTypeCoinDuplicate, ERR_COIN_FROM_COINS_ADDED_TWICE(v)
 		}
 		checkCoins[v] = 0
 	}
+	code, err := validateCoinsFormat(append(append([]string{}, sd.Coins...), sd.Fee...)...)
+	if err != nil {
+		return code, err
+	}
+
+	if len(sd.Memo) > models.MEMO_MAX_LENGTH {
+		return models.CodeTypeBadData, ERR_MEMO_TOO_LONG
+	}
+	if len(sd.EncryptedMemo) > models.ENCRYPTED_MEMO_MAX_LENGTH {
+		return models.CodeTypeBadData, ERR_ENCRYPTED_MEMO_TOO_LONG
+	}
+	if _, err := hex.DecodeString(sd.EncryptedMemo); err != nil {
+		return models.CodeTypeBadData, ERR_ENCRYPTED_MEMO_NOT_HEX
+	}
+	if len(sd.EncryptedSecret) > models.ENCRYPTED_SECRET_MAX_LENGTH {
+		return models.CodeTypeBadData, ERR_ENCRYPTED_SECRET_TOO_LONG
+	}
+	if _, err := hex.DecodeString(sd.EncryptedSecret); err != nil {
+		return models.CodeTypeBadData, ERR_ENCRYPTED_SECRET_NOT_HEX
+	}
+	if len(sd.Recipient) > 0 {
+		if _, err := utils.UnmarshalPublicKey(sd.Recipient); err != nil {
+			return models.CodeTypeBadData, ERR_RECIPIENT_NOT_CORRECT
+		}
+	}
+	if sd.IsStealth() {
+		code, err := validateStealth(s, sd)
+		if err != nil {
+			return code, err
+		}
+	}
 
-	tax := s.GetTax()
+	// the coins and the fee are paid in one asset, with the tax of the asset
+	asset, code, err := coinsAsset(s, append(append([]string{}, sd.Coins...), sd.Fee...))
+	if err != nil {
+		return code, err
+	}
+	tax := s.GetAssetTax(asset)
 	if tax.Percentage > 0 {
 		if len(sd.Fee) == 0 {
-			return models.CodeTypeUnauthorized, ERR_FEES_EMPTY
+			return models.CodeTypeFeeInsufficient, ERR_FEES_EMPTY
 		}
 	}
 
@@ -63,7 +112,7 @@ func ValidateSend(s *dbpkg.State, sd models.SendData, sig []byte) (uint32, error
 	for _, v := range sd.Fee {
 		_, ok := checkFees[v]
 		if ok {
-			return models.CodeTypeUnauthorized, ERR_COIN_FROM_FEE_ADDED_TWICE(v)
+			return models.CodeTypeCoinDuplicate, ERR_COIN_FROM_FEE_ADDED_TWICE(v)
 		}
 		checkFees[v] = 0
 	}
@@ -73,7 +122,7 @@ func ValidateSend(s *dbpkg.State, sd models.SendData, sig []byte) (uint32, error
 	for _, v := range allCoins {
 		_, ok := checkAllCoins[v]
 		if ok {
-			return models.CodeTypeUnauthorized, ERR_COIN_ADDED_ON_BOTH_COINS_AND_FEE(v)
+			return models.CodeTypeCoinDuplicate, ERR_COIN_ADDED_ON_BOTH_COINS_AND_FEE(v)
 		}
 		checkAllCoins[v] = 0
 	}
@@ -82,7 +131,7 @@ func ValidateSend(s *dbpkg.State, sd models.SendData, sig []byte) (uint32, error
 	for _, v := range sd.Coins {
 		c, err := s.GetCoin(v)
 		if err != nil {
-			return models.CodeTypeUnauthorized, ERR_COIN_FROM_COINS_DOES_NOT_EXISTS(v)
+			return models.CodeTypeCoinNotFound, ERR_COIN_FROM_COINS_DOES_NOT_EXISTS(v)
 		}
 		sumCoins += c.Value
 	}
@@ -91,15 +140,25 @@ func ValidateSend(s *dbpkg.State, sd models.SendData, sig []byte) (uint32, error
 	for _, v := range sd.Fee {
 		f, err := s.GetCoin(v)
 		if err != nil {
-			return models.CodeTypeUnauthorized, ERR_COIN_FROM_FEE_DOES_NOT_EXISTS(v)
+			return models.CodeTypeCoinNotFound, ERR_COIN_FROM_FEE_DOES_NOT_EXISTS(v)
 		}
 		sumFee += f.Value
 	}
-	taxFee := tax.GetFeeFromTransaction(sumCoins)
+	denominations := s.GetAssetDenominations(asset)
+	taxFee := tax.GetFeeFromTransaction(sumCoins, denominations)
 	if taxFee != 0 {
 		if taxFee > sumFee {
-			sub := utils.ToFixed(taxFee-sumFee, 2)
-			return models.CodeTypeUnauthorized, ERR_FEE_NOT_BASED_ON_TAX(sub)
+			sub := utils.ToFixed(taxFee-sumFee, models.DenominationsPrecision(denominations))
+			return models.CodeTypeFeeInsufficient, ERR_FEE_NOT_BASED_ON_TAX(sub)
+		}
+	}
+	// the fee pays also the demurrage of the coins and of the fee
+	demurrage := demurrageFee(s, asset, allCoins)
+	if demurrage > 0 {
+		required := utils.ToFixed(taxFee+demurrage, models.DenominationsPrecision(denominations))
+		if required > sumFee {
+			sub := utils.ToFixed(required-sumFee, models.DenominationsPrecision(denominations))
+			return models.CodeTypeFeeInsufficient, ERR_FEE_NOT_BASED_ON_DEMURRAGE(sub)
 		}
 	}
 
@@ -109,27 +168,60 @@ func ValidateSend(s *dbpkg.State, sd models.SendData, sig []byte) (uint32, error
 		allPubs = append(allPubs, c.Owner)
 	}
 
-	msg, _ := json.Marshal(sd)
 	isVer, err := utils.MultiVerify(allPubs, sig, msg)
 	if err != nil {
-		return models.CodeTypeEncodingError, err
+		return models.CodeTypeBadData, err
 	}
 	if !isVer {
-		return models.CodeTypeUnauthorized, ERR_SIGNATURE_NOT_VALID
+		return models.CodeTypeSignatureNotValid, ERR_SIGNATURE_NOT_VALID
 	}
 
-	_, err = sd.Proof.GetProof()
-	if err != nil {
-		return models.CodeTypeUnauthorized, ERR_PROOF_NOT_CORRECT
+	// the stealth owners do not need a proof, because they do not receive
+	if !sd.IsStealth() {
+		_, err = sd.Proof.GetProof()
+		if err != nil {
+			return models.CodeTypeProofNotValid, ERR_PROOF_NOT_CORRECT
+		}
 	}
 
 	for _, v := range allCoins {
 		isLocked, err := s.IsCoinLocked(v)
 		if err != nil {
-			return models.CodeTypeUnauthorized, err
+			return models.CodeTypeServerError, err
 		}
 		if isLocked {
-			return models.CodeTypeUnauthorized, ERR_COIN_IS_LOCKED(v)
+			return models.CodeTypeCoinLocked, ERR_COIN_IS_LOCKED(v)
+		}
+	}
+	return models.CodeTypeOK, nil
+}
+
+func validateStealth(s *dbpkg.State, sd models.SendData) (uint32, error) {
+	// the fields of a receive would be stored with the transaction without a meaning
+	if len(sd.Recipient) > 0 || len(sd.EncryptedSecret) > 0 || sd.Proof != (models.Proof{}) {
+		return models.CodeTypeBadData, ERR_STEALTH_WITH_RECEIVE
+	}
+	if _, err := utils.UnmarshalPublicKey(sd.StealthR); len(sd.StealthR) == 0 || err != nil {
+		return models.CodeTypeBadData, ERR_STEALTH_R_NOT_CORRECT
+	}
+	if len(sd.StealthOwners) != len(sd.Coins) {
+		return models.CodeTypeCoinNotInTransaction, ERR_STEALTH_OWNERS_NOT_EQUAL_TO_COINS
+	}
+	checkOwners := map[string]int{}
+	for _, coin := range sd.Coins {
+		owner, ok := sd.StealthOwners[coin]
+		if !ok {
+			return models.CodeTypeFieldEmpty, ERR_STEALTH_OWNER_MISSING(coin)
+		}
+		if _, ok := checkOwners[owner]; ok {
+			return models.CodeTypeCoinDuplicate, ERR_STEALTH_OWNERS_EQUAL
+		}
+		checkOwners[owner] = 0
+		if _, err := utils.UnmarshalPublicKey(owner); err != nil {
+			return models.CodeTypeBadData, err
+		}
+		if _, err := s.GetOwner(owner); err == nil {
+			return models.CodeTypeOwnerExists, ERR_OWNER_FROM_COINS_EXISTS_ALREADY(owner, coin)
 		}
 	}
 	return models.CodeTypeOK, nil
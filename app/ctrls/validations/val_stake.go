@@ -0,0 +1,115 @@
+package validations
+
+import (
+	"errors"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+)
+
+var (
+	ERR_STAKE_ONLY_MAIN_ASSET = func(uuid string) error {
+		return errors.New("The coin " + uuid + " is not of the main currency, only the main currency can be staked.")
+	}
+	ERR_STAKE_IS_UNBONDING = func(uuid string) error {
+		return errors.New("The coin " + uuid + " is unbonding already.")
+	}
+)
+
+// validateCoinsList checks that the coins are not empty, they have not been added twice
+// and the signature is not empty
+func validateCoinsList(coins []string, sig []byte) (uint32, error) {
+	if len(coins) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_COINS_EMPTY
+	}
+	checkCoins := map[string]int{}
+	for _, v := range coins {
+		if _, ok := checkCoins[v]; ok {
+			return models.CodeTypeCoinDuplicate, ERR_COIN_FROM_COINS_ADDED_TWICE(v)
+		}
+		checkCoins[v] = 0
+	}
+	if len(sig) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_SIGNATURE_EMPTY
+	}
+	return validateCoinsFormat(coins...)
+}
+
+func ValidateStake(s *dbpkg.State, sd models.StakeData, msg, sig []byte) (uint32, error) {
+	code, err := validateCoinsList(sd.Coins, sig)
+	if err != nil {
+		return code, err
+	}
+	if len(sd.Validator) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_VALIDATOR_PUBLIC_KEY_EMPTY
+	}
+	code, err = validateValidatorPubKey(sd.Validator)
+	if err != nil {
+		return code, err
+	}
+
+	ownersPubs := []string{}
+	for _, v := range sd.Coins {
+		sc, err := s.GetCoin(v)
+		if err != nil {
+			return models.CodeTypeCoinNotFound, ERR_COIN_FROM_COINS_DOES_NOT_EXISTS(v)
+		}
+		if sc.Asset != models.MAIN_ASSET {
+			return models.CodeTypeAssetNotCorrect, ERR_STAKE_ONLY_MAIN_ASSET(v)
+		}
+		ownersPubs = append(ownersPubs, sc.Owner)
+	}
+
+	isValid, err := utils.MultiVerify(ownersPubs, sig, msg)
+	if err != nil {
+		return models.CodeTypeBadData, err
+	}
+	if !isValid {
+		return models.CodeTypeSignatureNotValid, ERR_SIGNATURE_NOT_VALID
+	}
+
+	// the locked coins are in a transaction or they are staked already
+	for _, v := range sd.Coins {
+		isLocked, err := s.IsCoinLocked(v)
+		if err != nil {
+			return models.CodeTypeServerError, err
+		}
+		if isLocked {
+			return models.CodeTypeCoinLocked, ERR_COIN_IS_LOCKED(v)
+		}
+	}
+	return models.CodeTypeOK, nil
+}
+
+func ValidateUnstake(s *dbpkg.State, ud models.UnstakeData, msg, sig []byte) (uint32, error) {
+	code, err := validateCoinsList(ud.Coins, sig)
+	if err != nil {
+		return code, err
+	}
+
+	ownersPubs := []string{}
+	for _, v := range ud.Coins {
+		sc, err := s.GetCoin(v)
+		if err != nil {
+			return models.CodeTypeCoinNotFound, ERR_COIN_FROM_COINS_DOES_NOT_EXISTS(v)
+		}
+		ss, err := s.GetStake(v)
+		if err != nil {
+			return models.CodeTypeStakeNotCorrect, err
+		}
+		if ss.UnbondingHeight > 0 {
+			return models.CodeTypeStakeNotCorrect, ERR_STAKE_IS_UNBONDING(v)
+		}
+		ownersPubs = append(ownersPubs, sc.Owner)
+	}
+
+	isValid, err := utils.MultiVerify(ownersPubs, sig, msg)
+	if err != nil {
+		return models.CodeTypeBadData, err
+	}
+	if !isValid {
+		return models.CodeTypeSignatureNotValid, ERR_SIGNATURE_NOT_VALID
+	}
+	return models.CodeTypeOK, nil
+}
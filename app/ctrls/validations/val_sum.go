@@ -1,7 +1,6 @@
 package validations
 
 import (
-	"encoding/json"
 	"errors"
 
 	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
@@ -19,28 +18,36 @@ var (
 	ERR_NEW_OWNER_EXISTS_ALREADY  = errors.New("The new owner exists already.")
 )
 
-func ValidateSum(s *dbpkg.State, sd models.SumData, sig []byte) (uint32, error) {
+func ValidateSum(s *dbpkg.State, sd models.SumData, msg, sig []byte) (uint32, error) {
 	if len(sd.Coins) == 0 {
-		return models.CodeTypeUnauthorized, ERR_COINS_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_COINS_EMPTY
 	}
 
 	checkCoins := map[string]int{}
 	for _, v := range sd.Coins {
 		_, ok := checkCoins[v]
 		if ok {
-			return models.CodeTypeUnauthorized, ERR_COIN_FROM_COINS_ADDED_TWICE(v)
+			return models.CodeTypeCoinDuplicate, ERR_COIN_FROM_COINS_ADDED_TWICE(v)
 		}
 		checkCoins[v] = 0
 	}
 
 	if len(sig) == 0 {
-		return models.CodeTypeUnauthorized, ERR_SIGNATURE_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_SIGNATURE_EMPTY
 	}
 	if len(sd.NewCoin) == 0 {
-		return models.CodeTypeUnauthorized, ERR_NEW_COIN_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_NEW_COIN_EMPTY
 	}
 	if len(sd.NewOwner) == 0 {
-		return models.CodeTypeUnauthorized, ERR_NEW_OWNER_EMPTY
+		return models.CodeTypeFieldEmpty, ERR_NEW_OWNER_EMPTY
+	}
+	code, err := validateCoinsFormat(append([]string{sd.NewCoin}, sd.Coins...)...)
+	if err != nil {
+		return code, err
+	}
+	code, err = validatePublicKeysFormat(sd.NewOwner)
+	if err != nil {
+		return code, err
 	}
 
 	// check if the value is in the list of constants
@@ -49,50 +56,59 @@ func ValidateSum(s *dbpkg.State, sd models.SumData, sig []byte) (uint32, error)
 	for _, v := range sd.Coins {
 		sc, err := s.GetCoin(v)
 		if err != nil {
-			return models.CodeTypeUnauthorized, err
+			return models.CodeTypeCoinNotFound, err
 		}
 		sum += sc.Value
 		ownersPubs = append(ownersPubs, sc.Owner)
 	}
+	asset, code, err := coinsAsset(s, sd.Coins)
+	if err != nil {
+		return code, err
+	}
 
 	isFound := false
-	for _, v := range models.CONSTANT_VALUES {
+	for _, v := range s.GetAssetDenominations(asset) {
 		if v == sum {
 			isFound = true
 			break
 		}
 	}
 	if !isFound {
-		return models.CodeTypeUnauthorized, ERR_SUM_OF_COINS_NON_CONSTANT
+		return models.CodeTypeValueNotConstant, ERR_SUM_OF_COINS_NON_CONSTANT
 	}
 
 	// check if the new coin exists already
-	_, err := s.GetCoin(sd.NewCoin)
+	_, err = s.GetCoin(sd.NewCoin)
 	if err == nil {
-		return models.CodeTypeUnauthorized, ERR_NEW_COIN_EXISTS_ALREADY
+		return models.CodeTypeCoinExists, ERR_NEW_COIN_EXISTS_ALREADY
 	}
 
 	// check if the new owner exists already
 	_, err = s.GetOwner(sd.NewOwner)
 	if err == nil {
-		return models.CodeTypeUnauthorized, ERR_NEW_OWNER_EXISTS_ALREADY
+		return models.CodeTypeOwnerExists, ERR_NEW_OWNER_EXISTS_ALREADY
+	}
+
+	feeOwners, code, err := validateDemurrageFee(s, sd.Coins, sd.Fee)
+	if err != nil {
+		return code, err
 	}
-	msg, _ := json.Marshal(sd)
+	ownersPubs = append(ownersPubs, feeOwners...)
 	isValid, err := utils.MultiVerify(ownersPubs, sig, msg)
 	if err != nil {
-		return models.CodeTypeUnauthorized, err
+		return models.CodeTypeBadData, err
 	}
 	if !isValid {
-		return models.CodeTypeUnauthorized, ERR_SIGNATURE_NOT_VALID
+		return models.CodeTypeSignatureNotValid, ERR_SIGNATURE_NOT_VALID
 	}
 
 	for _, v := range sd.Coins {
 		isLocked, err := s.IsCoinLocked(v)
 		if err != nil {
-			return models.CodeTypeUnauthorized, err
+			return models.CodeTypeServerError, err
 		}
 		if isLocked {
-			return models.CodeTypeUnauthorized, ERR_COIN_IS_LOCKED(v)
+			return models.CodeTypeCoinLocked, ERR_COIN_IS_LOCKED(v)
 		}
 	}
 
@@ -0,0 +1,97 @@
+package validations
+
+import (
+	"encoding/hex"
+	"errors"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+)
+
+// ED25519_PUBLIC_KEY_SIZE is the size of the public keys of the tendermint validators
+const ED25519_PUBLIC_KEY_SIZE = 32
+
+var (
+	ERR_VALIDATOR_PUBLIC_KEY_EMPTY       = errors.New("The validator's public key is empty.")
+	ERR_VALIDATOR_PUBLIC_KEY_NOT_CORRECT = errors.New("The validator's public key is not a correct ed25519 public key in lowercase hex.")
+	ERR_VALIDATOR_POWER_NEGATIVE         = errors.New("The power of the validator can not be negative.")
+	ERR_VALIDATOR_NOT_ACTIVE             = errors.New("The validator can not be removed, because it does not have voting power.")
+	ERR_VALIDATORS_EMPTY                 = errors.New("The validator can not be removed, because it is the last validator with voting power.")
+)
+
+func ValidateValidator(s *dbpkg.State, vd models.ValidatorData, msg, sig []byte) (uint32, error) {
+	if len(vd.PubKey) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_VALIDATOR_PUBLIC_KEY_EMPTY
+	}
+	if len(sig) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_SIGNATURE_EMPTY
+	}
+	if len(vd.Inflator) == 0 {
+		return models.CodeTypeFieldEmpty, ERR_INFLATOR_EMPTY
+	}
+	code, err := validateValidatorPubKey(vd.PubKey)
+	if err != nil {
+		return code, err
+	}
+	if vd.Power < 0 {
+		return models.CodeTypeValidatorNotCorrect, ERR_VALIDATOR_POWER_NEGATIVE
+	}
+
+	code, err = validateValidatorsRemain(s, vd)
+	if err != nil {
+		return code, err
+	}
+
+	// the validators are set by the inflators of the main currency
+	code, err = validateInflatorOfAsset(s, models.MAIN_ASSET, vd.Inflator)
+	if err != nil {
+		return code, err
+	}
+
+	isVal, err := utils.Verify(vd.Inflator, sig, msg)
+	if err != nil {
+		return models.CodeTypeBadData, err
+	}
+	if !isVal {
+		return models.CodeTypeSignatureNotValid, ERR_SIGNATURE_NOT_VALID
+	}
+	return models.CodeTypeOK, nil
+}
+
+// validateValidatorsRemain checks that only the validators with voting power are removed,
+// and that the chain keeps at least one validator, because tendermint halts without validators
+func validateValidatorsRemain(s *dbpkg.State, vd models.ValidatorData) (uint32, error) {
+	if vd.Power == 0 {
+		sv, err := s.GetValidator(vd.PubKey)
+		if err != nil || sv.VotingPower() == 0 {
+			return models.CodeTypeValidatorNotCorrect, ERR_VALIDATOR_NOT_ACTIVE
+		}
+	}
+	total := int64(0)
+	for _, sv := range s.GetValidators() {
+		if sv.PubKey == vd.PubKey {
+			sv.Power = vd.Power
+		}
+		total += sv.VotingPower()
+	}
+	if vd.Power > 0 {
+		if _, err := s.GetValidator(vd.PubKey); err != nil {
+			total += vd.Power
+		}
+	}
+	if total == 0 {
+		return models.CodeTypeValidatorNotCorrect, ERR_VALIDATORS_EMPTY
+	}
+	return models.CodeTypeOK, nil
+}
+
+// validateValidatorPubKey checks that the public key is an ed25519 public key in lowercase hex,
+// so a validator has only one key in the state
+func validateValidatorPubKey(pubKey string) (uint32, error) {
+	pub, err := hex.DecodeString(pubKey)
+	if err != nil || len(pub) != ED25519_PUBLIC_KEY_SIZE || hex.EncodeToString(pub) != pubKey {
+		return models.CodeTypeValidatorNotCorrect, ERR_VALIDATOR_PUBLIC_KEY_NOT_CORRECT
+	}
+	return models.CodeTypeOK, nil
+}
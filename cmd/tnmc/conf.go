@@ -1,11 +1,24 @@
 package main
 
 import (
+	"errors"
 	"os"
+
+	client "github.com/tendermint/tendermint/rpc/client"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 )
 
 type configurations struct {
 	TendermintNode string
+	TrustNode      bool // skips the verification of the proofs that come with the queries
+
+	// the genesis file of the chain, or a file of the same format with a later validator set,
+	// the validators that sign the headers of the proofs are verified against its validators
+	TrustedValidators string
+
+	// the id of the chain that the deliveries are signed for, the node is asked for it when it is empty
+	ChainID string
 }
 
 var Confs = configurations{}
@@ -17,4 +30,30 @@ func init() {
 	} else {
 		Confs.TendermintNode = "tcp://localhost:26657"
 	}
+	Confs.TrustNode = len(os.Getenv("TRUST_NODE")) > 0
+	Confs.TrustedValidators = os.Getenv("TRUSTED_VALIDATORS")
+	Confs.ChainID = os.Getenv("CHAIN_ID")
+}
+
+// chainID returns the id of the chain from the configuration, or else from the node
+func chainID() (string, error) {
+	if len(Confs.ChainID) > 0 {
+		return Confs.ChainID, nil
+	}
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+	st, err := cli.Status()
+	if err != nil {
+		return "", errors.New("Error: could not get the chain id from the node, set CHAIN_ID, " + err.Error())
+	}
+	Confs.ChainID = st.NodeInfo.Network
+	return Confs.ChainID, nil
+}
+
+// signBytes returns the bytes that the owners sign for the delivery on the chain
+func signBytes(t models.DeliveryType, data interface{}) ([]byte, error) {
+	id, err := chainID()
+	if err != nil {
+		return nil, err
+	}
+	return models.SignBytes(id, t, data)
 }
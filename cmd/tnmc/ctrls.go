@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
 	"github.com/urfave/cli"
 )
@@ -62,6 +64,10 @@ var InflateCommand = cli.Command{
 			Name:  "value",
 			Usage: "the value of the new coin.",
 		},
+		cli.StringFlag{
+			Name:  "asset",
+			Usage: "the asset of the new coin, the main currency when it is empty.",
+		},
 	},
 	Usage: "Creates a new coin in the system and saves it in the vault's folder.",
 	Action: func(c *cli.Context) error {
@@ -73,6 +79,15 @@ var InflateCommand = cli.Command{
 		os.MkdirAll(vault, 0744)
 
 		value := c.Float64("value")
+		asset := c.String("asset")
+		denominations, err := getDenominations(asset)
+		if err != nil {
+			return err
+		}
+		err = checkDenominations(denominations, []float64{value})
+		if err != nil {
+			return err
+		}
 		keyB, err := ioutil.ReadFile(key)
 		if err != nil {
 			return errors.New("Error: could not read the file that contains the key of the inflator, " + err.Error())
@@ -83,7 +98,7 @@ var InflateCommand = cli.Command{
 		if err != nil {
 			return errors.New("Error: could not read the json format that contains the key of the inflator, " + err.Error())
 		}
-		filename, err := inflate(inflatorKpj, vault, value)
+		filename, err := inflate(inflatorKpj, vault, asset, value)
 		if err != nil {
 			return err
 		}
@@ -104,6 +119,10 @@ var SumCommand = cli.Command{
 			Name:  "coins",
 			Usage: "the list of coins seperated by comma.",
 		},
+		cli.StringFlag{
+			Name:  "fee",
+			Usage: "the list of coins for the demurrage seperated by comma, when the tax has a demurrage.",
+		},
 	},
 	Usage: "Sum two coins from the vault's folder and create a new one.",
 	Action: func(c *cli.Context) error {
@@ -117,7 +136,11 @@ var SumCommand = cli.Command{
 		}
 
 		coinsList := strings.Split(coinsListStr, ",")
-		filename, err := sum(coinsList, vault)
+		feeList := []string{}
+		if feeListStr := c.String("fee"); len(feeListStr) > 0 {
+			feeList = strings.Split(feeListStr, ",")
+		}
+		filename, err := sum(coinsList, feeList, vault)
 		if err != nil {
 			return err
 		}
@@ -142,6 +165,10 @@ var DivideCommand = cli.Command{
 			Name:  "values",
 			Usage: "the values for the new coins that you expect.",
 		},
+		cli.StringFlag{
+			Name:  "fee",
+			Usage: "the list of coins for the demurrage seperated by comma, when the tax has a demurrage.",
+		},
 	},
 	Usage: "Divide a coin into smaller coins",
 	Action: func(c *cli.Context) error {
@@ -160,6 +187,14 @@ var DivideCommand = cli.Command{
 			return errors.New("Error: values is empty")
 		}
 
+		cj, err := readCoin(vault, coin)
+		if err != nil {
+			return err
+		}
+		denominations, err := getDenominations(cj.Asset)
+		if err != nil {
+			return err
+		}
 		valuesStrList := strings.Split(valuesStr, ",")
 		values := []float64{}
 		for _, vs := range valuesStrList {
@@ -167,10 +202,18 @@ var DivideCommand = cli.Command{
 			if err != nil {
 				return errors.New("Error: The value " + vs + " does not parse.")
 			}
-			fxVal := utils.ToFixed(val, 2)
+			fxVal := utils.ToFixed(val, models.DenominationsPrecision(denominations))
 			values = append(values, fxVal)
 		}
-		filenames, err := divide(vault, coin, values)
+		err = checkDenominations(denominations, values)
+		if err != nil {
+			return err
+		}
+		feeList := []string{}
+		if feeListStr := c.String("fee"); len(feeListStr) > 0 {
+			feeList = strings.Split(feeListStr, ",")
+		}
+		filenames, err := divide(vault, coin, values, feeList)
 		if err != nil {
 			return err
 		}
@@ -193,6 +236,14 @@ var TaxCommand = cli.Command{
 			Name:  "percent",
 			Usage: "the percentage of the transactions.",
 		},
+		cli.StringFlag{
+			Name:  "asset",
+			Usage: "the asset of the transactions, the main currency when it is empty.",
+		},
+		cli.IntFlag{
+			Name:  "demurrage",
+			Usage: "the millionths of the coin's value that holding it costs for every block.",
+		},
 	},
 	Usage: "Create the tax for the transactions after it.",
 	Action: func(c *cli.Context) error {
@@ -212,7 +263,7 @@ var TaxCommand = cli.Command{
 			return errors.New("Error: could not read the json format that contains the key of the inflator, " + err.Error())
 		}
 
-		err = tax(inflatorKpj, c.Int("percent"))
+		err = tax(inflatorKpj, c.Int("percent"), c.String("asset"), c.Int("demurrage"))
 		if err != nil {
 			return err
 		}
@@ -221,16 +272,310 @@ var TaxCommand = cli.Command{
 	},
 }
 
+var InflatorsDocumentCommand = cli.Command{
+	Name:  "inflators_document",
+	Usage: "Create the document of the next version of the inflators, for the previous inflators to sign.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "filename",
+			Usage: "the file of the document.",
+		},
+		cli.StringFlag{
+			Name:  "previous",
+			Usage: "the IPFS hash of the previous version, empty for the first version.",
+		},
+		cli.Int64Flag{
+			Name:  "version",
+			Usage: "the version of the inflators, the version of the previous inflators plus one.",
+		},
+		cli.Int64Flag{
+			Name:  "activation-height",
+			Usage: "the height that the inflators are used from.",
+		},
+		cli.StringFlag{
+			Name:  "inflators",
+			Usage: "the list of the public keys of the inflators seperated by comma.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		filename := c.String("filename")
+		if len(filename) == 0 {
+			return errors.New("Error: filename is missing")
+		}
+		inflatorsStr := c.String("inflators")
+		if len(inflatorsStr) == 0 {
+			return errors.New("Error: inflators is empty")
+		}
+		err := newInflatorsDocument(filename, c.String("previous"), c.Int64("version"), c.Int64("activation-height"), strings.Split(inflatorsStr, ","))
+		if err != nil {
+			return err
+		}
+		fmt.Println("The document has been saved in " + filename)
+		return nil
+	},
+}
+
+var SignInflatorsCommand = cli.Command{
+	Name:  "sign_inflators",
+	Usage: "Sign the document of the next inflators with the key of a previous inflator.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "key",
+			Usage: "the filename of the inflator's key pair.",
+		},
+		cli.StringFlag{
+			Name:  "filename",
+			Usage: "the file of the document.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		key := c.String("key")
+		if len(key) == 0 {
+			return errors.New("Error: key is missing")
+		}
+		filename := c.String("filename")
+		if len(filename) == 0 {
+			return errors.New("Error: filename is missing")
+		}
+
+		keyB, err := ioutil.ReadFile(key)
+		if err != nil {
+			return errors.New("Error: could not read the file that contains the key of the inflator, " + err.Error())
+		}
+
+		inflatorKpj := KeyPairJson{}
+		err = json.Unmarshal(keyB, &inflatorKpj)
+		if err != nil {
+			return errors.New("Error: could not read the json format that contains the key of the inflator, " + err.Error())
+		}
+
+		err = signInflatorsDocument(filename, inflatorKpj)
+		if err != nil {
+			return err
+		}
+		fmt.Println("The document has been signed.")
+		return nil
+	},
+}
+
 var GetLatestTaxCommand = cli.Command{
 	Name:  "get_latest_tax",
 	Usage: "Get latest tax.",
+	Flags: []cli.Flag{
+		cli.Int64Flag{
+			Name:  "height",
+			Usage: "the height of the state, the latest when it is empty.",
+		},
+		cli.StringFlag{
+			Name:  "asset",
+			Usage: "the asset of the tax, the main currency when it is empty.",
+		},
+	},
 	Action: func(c *cli.Context) error {
-		qmt, err := getLatestTax()
+		qmt, err := getLatestTax(c.Int64("height"), c.String("asset"))
 		if err != nil {
 			return err
 		}
 		fmt.Println("Percent: ", qmt.Percentage)
 		fmt.Println("Inflator: ", qmt.Inflator)
+		if qmt.Demurrage > 0 {
+			fmt.Println("Demurrage: ", qmt.Demurrage)
+			fmt.Println("Demurrage since: ", qmt.DemurrageHeight)
+		}
+		return nil
+	},
+}
+
+var SetValidatorCommand = cli.Command{
+	Name: "set_validator",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "key",
+			Usage: "the filename of the inflator's key pair.",
+		},
+		cli.StringFlag{
+			Name:  "pub-key",
+			Usage: "the ed25519 public key hex of the validator, from the priv_validator.json of its node.",
+		},
+		cli.Int64Flag{
+			Name:  "power",
+			Usage: "the voting power of the validator, zero removes the validator.",
+		},
+	},
+	Usage: "Add, re-weight or remove a validator of the network.",
+	Action: func(c *cli.Context) error {
+		key := c.String("key")
+		if len(key) == 0 {
+			return errors.New("Error: key is missing")
+		}
+		pubKey := c.String("pub-key")
+		if len(pubKey) == 0 {
+			return errors.New("Error: pub-key is missing")
+		}
+
+		keyB, err := ioutil.ReadFile(key)
+		if err != nil {
+			return errors.New("Error: could not read the file that contains the key of the inflator, " + err.Error())
+		}
+
+		inflatorKpj := KeyPairJson{}
+		err = json.Unmarshal(keyB, &inflatorKpj)
+		if err != nil {
+			return errors.New("Error: could not read the json format that contains the key of the inflator, " + err.Error())
+		}
+
+		err = setValidator(inflatorKpj, strings.ToLower(pubKey), c.Int64("power"))
+		if err != nil {
+			return err
+		}
+		fmt.Println("The validator has been submitted.")
+		return nil
+	},
+}
+
+var ListValidatorsCommand = cli.Command{
+	Name:  "list_validators",
+	Usage: "List the validators that the inflators have set.",
+	Action: func(c *cli.Context) error {
+		qmvs, err := listValidators()
+		if err != nil {
+			return err
+		}
+		for _, qmv := range qmvs {
+			fmt.Println("PubKey: ", qmv.PubKey)
+			fmt.Println("Power: ", qmv.Power)
+			fmt.Println("Staked: ", qmv.Staked)
+			fmt.Println("Voting power: ", qmv.VotingPower)
+		}
+		return nil
+	},
+}
+
+var StakeCommand = cli.Command{
+	Name: "stake",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "vault",
+			Usage: "the folder that will contain all the coins.",
+		},
+		cli.StringFlag{
+			Name:  "coins",
+			Usage: "the list of coins seperated by comma.",
+		},
+		cli.StringFlag{
+			Name:  "validator",
+			Usage: "the ed25519 public key hex of the validator.",
+		},
+	},
+	Usage: "Lock the coins to back the voting power of a validator.",
+	Action: func(c *cli.Context) error {
+		vault := c.String("vault")
+		if len(vault) == 0 {
+			return errors.New("Error: vault is empty")
+		}
+		coinsListStr := c.String("coins")
+		if len(coinsListStr) == 0 {
+			return errors.New("Error: coins is empty")
+		}
+		validator := c.String("validator")
+		if len(validator) == 0 {
+			return errors.New("Error: validator is empty")
+		}
+		err := stake(vault, strings.Split(coinsListStr, ","), strings.ToLower(validator))
+		if err != nil {
+			return err
+		}
+		fmt.Println("The coins have been staked.")
+		return nil
+	},
+}
+
+var UnstakeCommand = cli.Command{
+	Name: "unstake",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "vault",
+			Usage: "the folder that will contain all the coins.",
+		},
+		cli.StringFlag{
+			Name:  "coins",
+			Usage: "the list of coins seperated by comma.",
+		},
+	},
+	Usage: "Unstake the coins, they are unlocked after the unbonding period.",
+	Action: func(c *cli.Context) error {
+		vault := c.String("vault")
+		if len(vault) == 0 {
+			return errors.New("Error: vault is empty")
+		}
+		coinsListStr := c.String("coins")
+		if len(coinsListStr) == 0 {
+			return errors.New("Error: coins is empty")
+		}
+		err := unstake(vault, strings.Split(coinsListStr, ","))
+		if err != nil {
+			return err
+		}
+		fmt.Println("The coins are unbonding.")
+		return nil
+	},
+}
+
+var GetStakesCommand = cli.Command{
+	Name:  "get_stakes",
+	Usage: "Get the coins that have been staked to a validator.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "validator",
+			Usage: "the ed25519 public key hex of the validator.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		validator := c.String("validator")
+		if len(validator) == 0 {
+			return errors.New("Error: validator is empty")
+		}
+		qmss, err := getStakes(strings.ToLower(validator))
+		if err != nil {
+			return err
+		}
+		for _, qms := range qmss {
+			fmt.Println("Coin: ", qms.Coin)
+			fmt.Println("Value: ", qms.Value)
+			if qms.UnbondingHeight > 0 {
+				fmt.Println("Unbonding until: ", qms.UnbondingHeight)
+			}
+		}
+		return nil
+	},
+}
+
+var ListSlashesCommand = cli.Command{
+	Name:  "list_slashes",
+	Usage: "List the coins that have been slashed from the validators for their misbehavior.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "validator",
+			Usage: "the ed25519 public key hex of the validator, all the validators without it.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		qmss, err := listSlashes(strings.ToLower(c.String("validator")))
+		if err != nil {
+			return err
+		}
+		for _, qms := range qmss {
+			fmt.Println("Validator: ", qms.Validator)
+			fmt.Println("Height: ", qms.Height)
+			fmt.Println("Evidence height: ", qms.EvidenceHeight)
+			fmt.Println("Type: ", qms.Type)
+			fmt.Println("Percentage: ", qms.Percentage)
+			fmt.Println("Coins: ", strings.Join(qms.Coins, ","))
+			fmt.Println("Value: ", qms.Value)
+			if len(qms.Hash) > 0 {
+				fmt.Println("Hash: ", qms.Hash)
+			}
+		}
 		return nil
 	},
 }
@@ -251,6 +596,26 @@ var SendCommand = cli.Command{
 			Name:  "fee",
 			Usage: "the list of coins for the fee seperated by comma.",
 		},
+		cli.StringFlag{
+			Name:  "memo",
+			Usage: "the reference of the payment for the receiver.",
+		},
+		cli.StringFlag{
+			Name:  "memo-key",
+			Usage: "the public key of the receiver, so only the receiver can read the memo.",
+		},
+		cli.StringFlag{
+			Name:  "secret-key",
+			Usage: "the public key of the receiver, to store the secret encrypted in the transaction.",
+		},
+		cli.StringFlag{
+			Name:  "recipient",
+			Usage: "the public key of the only receiver that can receive the coins.",
+		},
+		cli.StringFlag{
+			Name:  "stealth",
+			Usage: "the stealth address of the receiver, to give the coins to one-time owners without a receive.",
+		},
 	},
 	Action: func(c *cli.Context) error {
 		vault := c.String("vault")
@@ -269,12 +634,31 @@ var SendCommand = cli.Command{
 		coinsList := strings.Split(coinsListStr, ",")
 		feeList := strings.Split(feeListStr, ",")
 
-		hash, secret, err := send(coinsList, feeList, vault)
+		memo := c.String("memo")
+		if len(memo) > models.MEMO_MAX_LENGTH {
+			return errors.New("Error: memo is over " + strconv.Itoa(models.MEMO_MAX_LENGTH) + " bytes")
+		}
+		opts := sendOptions{}
+		opts.Memo = memo
+		opts.MemoKey = c.String("memo-key")
+		opts.SecretKey = c.String("secret-key")
+		opts.Recipient = c.String("recipient")
+		opts.StealthAddress = c.String("stealth")
+		if len(opts.StealthAddress) > 0 && (len(opts.Recipient) > 0 || len(opts.SecretKey) > 0) {
+			return errors.New("Error: stealth can not be used with recipient or secret-key")
+		}
+		hash, secret, err := send(coinsList, feeList, vault, opts)
 		if err != nil {
 			return err
 		}
 		fmt.Println("Hash: ", hash)
-		fmt.Println("Secret: ", secret)
+		if len(opts.StealthAddress) > 0 {
+			fmt.Println("The coins have been sent to the stealth address.")
+		} else if len(opts.SecretKey) > 0 {
+			fmt.Println("The secret has been encrypted in the transaction for the receiver.")
+		} else {
+			fmt.Println("Secret: ", secret)
+		}
 		return nil
 	},
 }
@@ -287,16 +671,45 @@ var GetTransactionCommand = cli.Command{
 			Name:  "hash",
 			Usage: "the hash of the transaction.",
 		},
+		cli.StringFlag{
+			Name:  "key",
+			Usage: "the filename of the receiver's key pair, to decrypt the memo.",
+		},
+		cli.Int64Flag{
+			Name:  "height",
+			Usage: "the height of the state, the latest when it is empty.",
+		},
 	},
 	Action: func(c *cli.Context) error {
-		qmt, err := getTransaction(c.String("hash"))
+		qmt, err := getTransaction(c.String("hash"), c.Int64("height"))
 		if err != nil {
 			return err
 		}
 		fmt.Println("Hash: ", qmt.Hash)
 		fmt.Println("Coins: ", qmt.Coins)
 		fmt.Println("Fee: ", qmt.Fee)
+		if len(qmt.Recipient) > 0 {
+			fmt.Println("Recipient: ", qmt.Recipient)
+		}
+		if len(qmt.Memo) > 0 {
+			fmt.Println("Memo: ", qmt.Memo)
+		}
+		if len(qmt.EncryptedMemo) > 0 {
+			key := c.String("key")
+			if len(key) == 0 {
+				fmt.Println("Encrypted memo: ", qmt.EncryptedMemo)
+			} else {
+				memo, err := decryptMemo(key, qmt.EncryptedMemo)
+				if err != nil {
+					return err
+				}
+				fmt.Println("Memo: ", memo)
+			}
+		}
 		fmt.Println("The coins have been received: ", qmt.IsCoinsReceived)
+		if !qmt.IsCoinsReceived && len(qmt.ReceivedCoins) > 0 {
+			fmt.Println("The coins received until now: ", qmt.ReceivedCoins)
+		}
 		fmt.Println("The fee have been received: ", qmt.IsFeeReceived)
 		return nil
 	},
@@ -310,9 +723,13 @@ var GetCoin = cli.Command{
 			Name:  "coin",
 			Usage: "the uuid of the coin.",
 		},
+		cli.Int64Flag{
+			Name:  "height",
+			Usage: "the height of the state, the latest when it is empty.",
+		},
 	},
 	Action: func(c *cli.Context) error {
-		qmc, err := getCoin(c.String("coin"))
+		qmc, err := getCoin(c.String("coin"), c.Int64("height"))
 		if err != nil {
 			return err
 		}
@@ -324,6 +741,121 @@ var GetCoin = cli.Command{
 	},
 }
 
+var GetCoinHistoryCommand = cli.Command{
+	Name:  "get_coin_history",
+	Usage: "Get the history of a coin.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "coin",
+			Usage: "the uuid of the coin.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		qmchs, err := getCoinHistory(c.String("coin"))
+		if err != nil {
+			return err
+		}
+		for _, qmch := range qmchs {
+			fmt.Println("Height:", qmch.Height)
+			fmt.Println("Action:", qmch.Action)
+			if len(qmch.PreviousOwner) > 0 {
+				fmt.Println("Previous owner:", qmch.PreviousOwner)
+			}
+			if len(qmch.NewOwner) > 0 {
+				fmt.Println("New owner:", qmch.NewOwner)
+			}
+			fmt.Println("")
+		}
+		return nil
+	},
+}
+
+var VaultCommand = cli.Command{
+	Name:  "vault",
+	Usage: "Manage the vault.",
+	Subcommands: []cli.Command{
+		{
+			Name:  "status",
+			Usage: "Check the status of all the coins in the vault.",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "vault",
+					Usage: "the folder that contains all the coins.",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				vault := c.String("vault")
+				if len(vault) == 0 {
+					return errors.New("Error: vault is empty")
+				}
+				css, err := vaultStatus(vault)
+				if err != nil {
+					return err
+				}
+				total := 0.0
+				for _, cs := range css {
+					fmt.Println(cs.UUID, cs.Value, cs.Status)
+					if cs.Status == COIN_STATUS_OK {
+						total += cs.Value
+					}
+				}
+				fmt.Println("The value of the coins that can be spent: ", total)
+				return nil
+			},
+		},
+	},
+}
+
+var GenesisCommand = cli.Command{
+	Name:  "genesis",
+	Usage: "Prepare the genesis of the chain.",
+	Subcommands: []cli.Command{
+		{
+			Name:  "add-coins",
+			Usage: "Create coins in the vault and allocate them in the genesis.",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "vault",
+					Usage: "the folder that will contain the coins.",
+				},
+				cli.StringFlag{
+					Name:  "genesis",
+					Usage: "the genesis file of tendermint.",
+				},
+				cli.StringFlag{
+					Name:  "values",
+					Usage: "the values of the coins seperated by comma.",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				vault := c.String("vault")
+				if len(vault) == 0 {
+					return errors.New("Error: vault is empty")
+				}
+				genesis := c.String("genesis")
+				if len(genesis) == 0 {
+					return errors.New("Error: genesis is empty")
+				}
+				if len(c.String("values")) == 0 {
+					return errors.New("Error: values are empty")
+				}
+				values, err := parseValues(c.String("values"))
+				if err != nil {
+					return err
+				}
+				cjs, err := genesisAddCoins(vault, genesis, values)
+				if err != nil {
+					return err
+				}
+				for _, cj := range cjs {
+					fmt.Println(cj.UUID, cj.Value)
+				}
+				return nil
+			},
+		},
+	},
+}
+
 var ReceiveCoinsCommand = cli.Command{
 	Name:  "receive",
 	Usage: "Receive the coins from the transaction.",
@@ -336,6 +868,14 @@ var ReceiveCoinsCommand = cli.Command{
 			Name:  "secret",
 			Usage: "the secret that will prove the new owners of the coins .",
 		},
+		cli.StringFlag{
+			Name:  "key",
+			Usage: "the filename of the receiver's key pair, to decrypt the secret or to sign as the recipient.",
+		},
+		cli.StringFlag{
+			Name:  "coins",
+			Usage: "the list of coins from the transaction seperated by comma, to receive only them. Only for a transaction with a recipient.",
+		},
 		cli.StringFlag{
 			Name:  "vault",
 			Usage: "the folder that will contain all the coins.",
@@ -349,7 +889,8 @@ var ReceiveCoinsCommand = cli.Command{
 		os.MkdirAll(vault, 0744)
 
 		secret := c.String("secret")
-		if len(secret) == 0 {
+		key := c.String("key")
+		if len(secret) == 0 && len(key) == 0 {
 			return errors.New("Error: secret is empty")
 		}
 
@@ -358,7 +899,11 @@ var ReceiveCoinsCommand = cli.Command{
 			return errors.New("Error: hash is empty")
 		}
 
-		filenames, err := receive(vault, hash, secret)
+		coins := []string{}
+		if coinsListStr := c.String("coins"); len(coinsListStr) > 0 {
+			coins = strings.Split(coinsListStr, ",")
+		}
+		filenames, err := receive(vault, hash, secret, key, coins)
 		if err != nil {
 			return err
 		}
@@ -396,6 +941,86 @@ var GetTransactionsWithUnreceivedFeeCommand = cli.Command{
 	},
 }
 
+var ListTransactionsCommand = cli.Command{
+	Name:  "list_transactions",
+	Usage: "List the transactions page by page.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "fee-received",
+			Usage: "true or false, to list only the transactions that their fee has or has not been received.",
+		},
+		cli.StringFlag{
+			Name:  "coins-received",
+			Usage: "true or false, to list only the transactions that their coins have or have not been received.",
+		},
+		cli.StringFlag{
+			Name:  "coin",
+			Usage: "the uuid of a coin or fee that the transactions contain.",
+		},
+		cli.Int64Flag{
+			Name:  "from-height",
+			Usage: "the first height of the transactions.",
+		},
+		cli.Int64Flag{
+			Name:  "to-height",
+			Usage: "the last height of the transactions.",
+		},
+		cli.IntFlag{
+			Name:  "limit",
+			Usage: "how many transactions a page has.",
+		},
+		cli.StringFlag{
+			Name:  "after",
+			Usage: "the cursor of the page, from the previous page.",
+		},
+		cli.BoolFlag{
+			Name:  "all",
+			Usage: "list all the pages.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		filters := url.Values{}
+		for flag, filter := range map[string]string{"fee-received": "fee_received", "coins-received": "coins_received", "coin": "coin"} {
+			if len(c.String(flag)) > 0 {
+				filters.Set(filter, c.String(flag))
+			}
+		}
+		for flag, filter := range map[string]string{"from-height": "from_height", "to-height": "to_height"} {
+			if c.Int64(flag) > 0 {
+				filters.Set(filter, strconv.FormatInt(c.Int64(flag), 10))
+			}
+		}
+		if c.Int("limit") > 0 {
+			filters.Set("limit", strconv.Itoa(c.Int("limit")))
+		}
+
+		after := c.String("after")
+		for {
+			qmtl, err := listTransactions(filters, after)
+			if err != nil {
+				return err
+			}
+			for _, qmt := range qmtl.Transactions {
+				fmt.Println("Hash: ", qmt.Hash)
+				fmt.Println("Height: ", qmt.Height)
+				fmt.Println("Coins: ", qmt.Coins)
+				fmt.Println("Fee: ", qmt.Fee)
+				fmt.Println("The coins have been received: ", qmt.IsCoinsReceived)
+				fmt.Println("The fee have been received: ", qmt.IsFeeReceived)
+				fmt.Println("")
+			}
+			if len(qmtl.Next) == 0 {
+				return nil
+			}
+			if !c.Bool("all") {
+				fmt.Println("Next page: --after", qmtl.Next)
+				return nil
+			}
+			after = qmtl.Next
+		}
+	},
+}
+
 var ReceiveFeeCommand = cli.Command{
 	Name:  "receive_fee",
 	Usage: "Receive the fee from the transaction.",
@@ -452,3 +1077,63 @@ var ReceiveFeeCommand = cli.Command{
 		return nil
 	},
 }
+
+var GenerateStealthCommand = cli.Command{
+	Name:  "generate_stealth",
+	Usage: "generate the view and spend keys of a stealth address in a file",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "filename",
+			Usage: "the filename that the keys will be saved",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		filename := c.String("filename")
+		if len(filename) == 0 {
+			return errors.New("Error: filename is missing")
+		}
+		address, err := generateStealth(filename)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Stealth address: ", address)
+		return nil
+	},
+}
+
+var ScanCommand = cli.Command{
+	Name:  "scan",
+	Usage: "Scan the stealth transactions for the coins of the stealth address and save them in the vault's folder.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "key",
+			Usage: "the filename of the stealth keys.",
+		},
+		cli.StringFlag{
+			Name:  "vault",
+			Usage: "the folder that will contain all the coins.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		vault := c.String("vault")
+		if len(vault) == 0 {
+			return errors.New("Error: vault is empty")
+		}
+		os.MkdirAll(vault, 0744)
+
+		key := c.String("key")
+		if len(key) == 0 {
+			return errors.New("Error: key is missing")
+		}
+
+		filenames, err := scan(key, vault)
+		if err != nil {
+			return err
+		}
+		fmt.Println(len(filenames), " new coins have been found:")
+		for _, v := range filenames {
+			fmt.Println(v)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/query"
+	client "github.com/tendermint/tendermint/rpc/client"
+)
+
+var (
+	ERR_VALUE_NOT_DENOMINATION = func(value float64) error {
+		return errors.New(fmt.Sprint("Error: the value ", value, " is not one of the denominations of the network."))
+	}
+)
+
+// getDenominations returns the values that the coins of the asset can have
+func getDenominations(asset string) ([]float64, error) {
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+	q, err := cli.ABCIQuery("get_denominations?asset="+url.QueryEscape(asset), nil)
+	if err != nil {
+		return nil, errors.New("Error:" + err.Error())
+	}
+	if q.Response.Code > models.CodeTypeOK {
+		return nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
+	}
+	qmd := query.QueryModelDenominations{}
+	json.Unmarshal(q.Response.Value, &qmd)
+	return qmd.Denominations, nil
+}
+
+// checkDenominations checks that the values are denominations
+func checkDenominations(denominations, values []float64) error {
+	for _, v := range values {
+		if !models.IsDenomination(denominations, v) {
+			return ERR_VALUE_NOT_DENOMINATION(v)
+		}
+	}
+	return nil
+}
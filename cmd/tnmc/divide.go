@@ -19,7 +19,7 @@ import (
 	"io/ioutil"
 )
 
-func divide(vault, coin string, values []float64) ([]string, error) {
+func divide(vault, coin string, values []float64, fee []string) ([]string, error) {
 	coinFile, err := ioutil.ReadFile(vault + "/" + coin)
 	if err != nil {
 		return nil, errors.New("Error: The file for the coin " + coin + " is missing.")
@@ -51,6 +51,7 @@ func divide(vault, coin string, values []float64) ([]string, error) {
 		newOwnerPrivHex := hex.EncodeToString(newOwnerPrivB)
 		ncj.OwnerPrivateKey = newOwnerPrivHex
 		ncj.Value = val
+		ncj.Asset = cj.Asset
 		ncjs = append(ncjs, ncj)
 		privs = append(privs, newOwnerKp.Private)
 	}
@@ -64,22 +65,31 @@ func divide(vault, coin string, values []float64) ([]string, error) {
 		c.Value = ncj.Value
 		data.NewCoins[ncj.UUID] = c
 	}
+	data.Fee = fee
+	feePrivs, err := coinsPrivateKeys(vault, fee)
+	if err != nil {
+		return nil, err
+	}
+	privs = append(privs, feePrivs...)
 
 	d := models.Delivery{}
 	d.Type = models.DIVIDE
 	d.Data = data
-	msg, _ := json.Marshal(data)
+	msg, err := signBytes(d.Type, data)
+	if err != nil {
+		return nil, err
+	}
 	d.Signature, _ = utils.MultiSignature(privs, msg)
 
-	dB, _ := json.Marshal(d)
+	dB, _ := models.EncodeDelivery(d)
 
 	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
 	btc, err := cli.BroadcastTxCommit(types.Tx(dB))
 	if err != nil {
 		return nil, errors.New("Error: " + err.Error())
 	}
-	if btc.CheckTx.Code > models.CodeTypeOK {
-		return nil, errors.New("Error: " + btc.CheckTx.Log)
+	if err := broadcastError(btc); err != nil {
+		return nil, err
 	}
 
 	// save the new coins
@@ -94,8 +104,10 @@ func divide(vault, coin string, values []float64) ([]string, error) {
 		filenames = append(filenames, filename)
 	}
 
-	// delete the previous coin
-	os.Remove(vault + "/" + coin)
+	// delete the previous coin and the fee
+	for _, c := range append([]string{coin}, fee...) {
+		os.Remove(vault + "/" + c)
+	}
 
 	return filenames, nil
 }
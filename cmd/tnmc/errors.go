@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// CODE_HINTS are the actions that the user can take for the codes of the failed deliveries and queries
+var CODE_HINTS = map[uint32]string{
+	models.CodeTypeEncodingError:         "the client and the node do not have the same version",
+	models.CodeTypeServerError:           "the node could not process it, try again later",
+	models.CodeTypeBadData:               "check the format of the coins and the public keys",
+	models.CodeTypeFieldEmpty:            "fill in the required arguments",
+	models.CodeTypeSignatureNotValid:     "check that the vault has the private keys of all the coins",
+	models.CodeTypeInflatorNotInList:     "use the key of an inflator that the node accepts",
+	models.CodeTypeValueNotConstant:      "use only the values of the coins that are allowed",
+	models.CodeTypeCoinNotFound:          "the coin has been spent or it was never inflated, check the vault with 'vault status'",
+	models.CodeTypeCoinExists:            "the new coin exists already, try again to get a new uuid",
+	models.CodeTypeOwnerExists:           "the new owner exists already, try again to get a new key",
+	models.CodeTypeCoinLocked:            "the coin is in a transaction that has not been received",
+	models.CodeTypeCoinDuplicate:         "add each coin only once",
+	models.CodeTypeFeeInsufficient:       "add the coins of the fee based on the latest tax and its demurrage, with 'get_latest_tax'",
+	models.CodeTypeProofNotValid:         "check the secret of the transaction",
+	models.CodeTypeTransactionNotFound:   "check the hash of the transaction",
+	models.CodeTypeTransactionReceived:   "the coins of the transaction have been received already",
+	models.CodeTypeCoinNotInTransaction:  "use only the coins of the transaction",
+	models.CodeTypeTaxNotCorrect:         "the tax can be from 0 to 100 percent, and the demurrage from 0 to 1000000",
+	models.CodeTypeDeliveryTypeNotExists: "the client and the node do not have the same version",
+	models.CodeTypeAssetNotCorrect:       "use an asset that exists, and the coins of only one asset",
+	models.CodeTypeValidatorNotCorrect:   "use the ed25519 public key hex of an active validator and a power that is not negative, the last validator can not be removed",
+	models.CodeTypeStakeNotCorrect:       "unstake only the coins that are staked, check them with 'get_stakes'",
+	models.CodeTypeNotFound:              "nothing has been found for the arguments of the query",
+	models.CodeTypeHeightNotAvailable:    "use a height that has been committed and that the node has not pruned",
+	models.CodeTypeQueryNotExists:        "the client and the node do not have the same version",
+}
+
+// codeError returns the error of a failed response, with what the user can do about it
+func codeError(codespace string, code uint32, log string) error {
+	if len(codespace) > 0 && codespace != models.CODESPACE {
+		return errors.New(fmt.Sprintf("Error: %s (%s %d)", log, codespace, code))
+	}
+	hint, ok := CODE_HINTS[code]
+	if !ok {
+		return errors.New(fmt.Sprintf("Error: %s (%s)", log, models.CodeName(code)))
+	}
+	return errors.New(fmt.Sprintf("Error: %s (%s: %s)", log, models.CodeName(code), hint))
+}
+
+// broadcastError returns the error of the delivery, when the check or the delivery has failed
+func broadcastError(btc *ctypes.ResultBroadcastTxCommit) error {
+	if btc.CheckTx.Code > models.CodeTypeOK {
+		return codeError(btc.CheckTx.Codespace, btc.CheckTx.Code, btc.CheckTx.Log)
+	}
+	if btc.DeliverTx.Code > models.CodeTypeOK {
+		return codeError(btc.DeliverTx.Codespace, btc.DeliverTx.Code, btc.DeliverTx.Log)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	uuid "github.com/satori/go.uuid"
+)
+
+// parseValues parses the values of the coins seperated by comma
+func parseValues(values string) ([]float64, error) {
+	vs := []float64{}
+	for _, v := range strings.Split(values, ",") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, errors.New("Error: The value " + v + " does not parse.")
+		}
+		vs = append(vs, f)
+	}
+	return vs, nil
+}
+
+// genesisAddCoins creates the coins in the vault and adds them to the app_state of the genesis
+func genesisAddCoins(vault, genesis string, values []float64) ([]CoinJson, error) {
+	gb, err := ioutil.ReadFile(genesis)
+	if err != nil {
+		return nil, errors.New("Error: the file " + genesis + " has problem:" + err.Error())
+	}
+	// the genesis and its app_state are kept as maps, so the rest of the fields do not change
+	gen := map[string]json.RawMessage{}
+	err = json.Unmarshal(gb, &gen)
+	if err != nil {
+		return nil, errors.New("Error: the file " + genesis + " has problem with encoding:" + err.Error())
+	}
+	appState := map[string]json.RawMessage{}
+	if len(gen["app_state"]) > 0 && string(gen["app_state"]) != "null" {
+		err = json.Unmarshal(gen["app_state"], &appState)
+		if err != nil {
+			return nil, errors.New("Error: the app_state of the genesis has problem with encoding:" + err.Error())
+		}
+	}
+	// the values are checked against the denominations of the genesis,
+	// the CONSTANT_VALUES are copied only for a genesis without denominations
+	denominations := []float64{}
+	if len(appState["denominations"]) > 0 && string(appState["denominations"]) != "null" {
+		err = json.Unmarshal(appState["denominations"], &denominations)
+		if err != nil {
+			return nil, errors.New("Error: the denominations of the genesis have problem with encoding:" + err.Error())
+		}
+	} else {
+		denominations = append(denominations, models.CONSTANT_VALUES...)
+	}
+	err = checkDenominations(denominations, values)
+	if err != nil {
+		return nil, err
+	}
+
+	gcs := []models.GenesisCoin{}
+	if len(appState["coins"]) > 0 {
+		err = json.Unmarshal(appState["coins"], &gcs)
+		if err != nil {
+			return nil, errors.New("Error: the coins of the genesis have problem with encoding:" + err.Error())
+		}
+	}
+
+	cjs := []CoinJson{}
+	for _, value := range values {
+		ownerKp, ownerPubHex := utils.CreateKeyPair()
+		ownerPrivB, _ := ownerKp.Private.MarshalBinary()
+		cj := CoinJson{}
+		cj.UUID = uuid.NewV4().String()
+		cj.Value = value
+		cj.OwnerPrivateKey = hex.EncodeToString(ownerPrivB)
+		cj.OwnerPublicKey = ownerPubHex
+		cjs = append(cjs, cj)
+		gcs = append(gcs, models.GenesisCoin{Coin: cj.UUID, Value: cj.Value, Owner: cj.OwnerPublicKey})
+	}
+
+	appState["coins"], _ = json.Marshal(gcs)
+	gen["app_state"], _ = json.Marshal(appState)
+	gb, _ = json.MarshalIndent(gen, "", "  ")
+
+	// the coins are saved in the vault before the genesis, so a coin of the genesis is never lost
+	for _, cj := range cjs {
+		coinFileB, _ := json.Marshal(cj)
+		err = ioutil.WriteFile(vault+"/"+cj.UUID, coinFileB, 0644)
+		if err != nil {
+			return nil, errors.New("Error: failed to save the coin " + cj.UUID + " in the vault: " + err.Error())
+		}
+	}
+	err = ioutil.WriteFile(genesis, gb, 0644)
+	if err != nil {
+		return nil, errors.New("Error: " + err.Error())
+	}
+	return cjs, nil
+}
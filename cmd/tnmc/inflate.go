@@ -15,7 +15,7 @@ import (
 	"github.com/tendermint/tendermint/types"
 )
 
-func inflate(inflatorKpj KeyPairJson, vault string, value float64) (string, error) {
+func inflate(inflatorKpj KeyPairJson, vault, asset string, value float64) (string, error) {
 	suite := edwards25519.NewBlakeSHA256Ed25519()
 	inflatorPrivateKeyB, _ := hex.DecodeString(inflatorKpj.PrivateKey)
 	inflatorPrivateKey := suite.Scalar()
@@ -27,13 +27,17 @@ func inflate(inflatorKpj KeyPairJson, vault string, value float64) (string, erro
 	data.Coin = uuid.NewV4().String()
 	data.Value = value
 	data.Owner = ownerPubHex
+	data.Asset = asset
 	d := models.Delivery{}
 	d.Type = models.INFLATE
 	d.Data = data
-	msg, _ := json.Marshal(data)
+	msg, err := signBytes(d.Type, data)
+	if err != nil {
+		return "", err
+	}
 	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{inflatorPrivateKey, ownerKp.Private}, msg)
 
-	dB, _ := json.Marshal(d)
+	dB, _ := models.EncodeDelivery(d)
 
 	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
 
@@ -41,14 +45,15 @@ func inflate(inflatorKpj KeyPairJson, vault string, value float64) (string, erro
 	if err != nil {
 		return "", errors.New("Error: " + err.Error())
 	}
-	if btc.CheckTx.Code > models.CodeTypeOK {
-		return "", errors.New("Error: " + btc.CheckTx.Log)
+	if err := broadcastError(btc); err != nil {
+		return "", err
 	}
 
 	// after the success, we save the coin in the vault
 	newCoin := CoinJson{}
 	newCoin.UUID = data.Coin
 	newCoin.Value = data.Value
+	newCoin.Asset = data.Asset
 	ownerPrivB, _ := ownerKp.Private.MarshalBinary()
 	ownerPrivHex := hex.EncodeToString(ownerPrivB)
 	newCoin.OwnerPrivateKey = ownerPrivHex
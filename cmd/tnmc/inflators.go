@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	"github.com/dedis/kyber/group/edwards25519"
+	"github.com/mragiadakos/tendermoney/app/confs"
+)
+
+func newInflatorsDocument(filename, previous string, version, activationHeight int64, inflators []string) error {
+	d := confs.InflatorsDocument{}
+	d.Version = version
+	d.ActivationHeight = activationHeight
+	d.Inflators = inflators
+	d.Previous = previous
+	b, _ := json.MarshalIndent(d, "", "  ")
+	err := ioutil.WriteFile(filename, b, 0644)
+	if err != nil {
+		return errors.New("Error: " + err.Error())
+	}
+	return nil
+}
+
+// signInflatorsDocument adds the signature of the inflator to the document of the next inflators
+func signInflatorsDocument(filename string, inflatorKpj KeyPairJson) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return errors.New("Error: could not read the document of the inflators, " + err.Error())
+	}
+	d := confs.InflatorsDocument{}
+	err = json.Unmarshal(b, &d)
+	if err != nil {
+		return errors.New("Error: could not read the json format of the document of the inflators, " + err.Error())
+	}
+
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	inflatorPrivateKeyB, _ := hex.DecodeString(inflatorKpj.PrivateKey)
+	inflatorPrivateKey := suite.Scalar()
+	err = inflatorPrivateKey.UnmarshalBinary(inflatorPrivateKeyB)
+	if err != nil {
+		return errors.New("Error: the private key of the inflator is not correct.")
+	}
+	err = d.Sign(inflatorKpj.PublicKey, inflatorPrivateKey)
+	if err != nil {
+		return errors.New("Error: " + err.Error())
+	}
+	b, _ = json.MarshalIndent(d, "", "  ")
+	err = ioutil.WriteFile(filename, b, 0644)
+	if err != nil {
+		return errors.New("Error: " + err.Error())
+	}
+	return nil
+}
@@ -16,12 +16,26 @@ func main() {
 		DivideCommand,
 		TaxCommand,
 		GetLatestTaxCommand,
+		InflatorsDocumentCommand,
+		SignInflatorsCommand,
+		SetValidatorCommand,
+		ListValidatorsCommand,
+		StakeCommand,
+		UnstakeCommand,
+		GetStakesCommand,
+		ListSlashesCommand,
 		SendCommand,
 		GetTransactionCommand,
 		GetCoin,
+		GetCoinHistoryCommand,
+		VaultCommand,
+		GenesisCommand,
 		ReceiveCoinsCommand,
 		GetTransactionsWithUnreceivedFeeCommand,
+		ListTransactionsCommand,
 		ReceiveFeeCommand,
+		GenerateStealthCommand,
+		ScanCommand,
 	}
 	err := app.Run(os.Args)
 	if err != nil {
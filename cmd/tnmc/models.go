@@ -10,4 +10,13 @@ type CoinJson struct {
 	OwnerPublicKey  string
 	UUID            string
 	Value           float64
+	Asset           string `json:",omitempty"`
+}
+
+type StealthKeyJson struct {
+	Address         string
+	ViewPublicKey   string
+	ViewPrivateKey  string
+	SpendPublicKey  string
+	SpendPrivateKey string
 }
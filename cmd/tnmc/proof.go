@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	client "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/tendermint/tendermint/types"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+)
+
+// how many seconds to wait for the block that commits the app hash of the query
+const COMMIT_RETRIES = 10
+
+// provenQuery queries the node and verifies the proofs of the response against the app hash,
+// that has been signed by the trusted validators in the next block.
+// When the node is trusted, the proofs are not requested.
+// The zero height is the latest height.
+func provenQuery(path string, height int64) (*abci.ResponseQuery, []dbpkg.KeyProof, error) {
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+	q, err := cli.ABCIQueryWithOptions(path, nil, client.ABCIQueryOptions{Height: height, Trusted: Confs.TrustNode})
+	if err != nil {
+		return nil, nil, errors.New("Error:" + err.Error())
+	}
+	if q.Response.Code > models.CodeTypeOK {
+		return nil, nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
+	}
+	if Confs.TrustNode {
+		return &q.Response, nil, nil
+	}
+
+	kps := []dbpkg.KeyProof{}
+	err = json.Unmarshal(q.Response.Proof, &kps)
+	if err != nil {
+		return nil, nil, errors.New("Error: the proof of the query is not correct, " + err.Error())
+	}
+	err = verifyProofs(cli, q.Response.Height, kps)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &q.Response, kps, nil
+}
+
+// trustedValidators returns the chain id and the validators that the client trusts,
+// because the validators that the node returns could be made up by the node
+func trustedValidators() (string, *types.ValidatorSet, error) {
+	if len(Confs.TrustedValidators) == 0 {
+		return "", nil, errors.New("Error: the trusted validators are needed to verify the proofs, set TRUSTED_VALIDATORS to the genesis file of the chain or set TRUST_NODE.")
+	}
+	doc, err := types.GenesisDocFromFile(Confs.TrustedValidators)
+	if err != nil {
+		return "", nil, errors.New("Error: the file of the trusted validators is not correct, " + err.Error())
+	}
+	if len(doc.Validators) == 0 {
+		return "", nil, errors.New("Error: the file of the trusted validators does not have validators.")
+	}
+	vals := []*types.Validator{}
+	for _, gv := range doc.Validators {
+		vals = append(vals, types.NewValidator(gv.PubKey, gv.Power))
+	}
+	return doc.ChainID, types.NewValidatorSet(vals), nil
+}
+
+func verifyProofs(cli *client.HTTP, height int64, kps []dbpkg.KeyProof) error {
+	chainID, trusted, err := trustedValidators()
+	if err != nil {
+		return err
+	}
+
+	// the app hash of a height is part of the header of the next block
+	next := height + 1
+	var commit *ctypes.ResultCommit
+	for i := 0; ; i++ {
+		commit, err = cli.Commit(&next)
+		if err == nil {
+			break
+		}
+		if i == COMMIT_RETRIES {
+			return errors.New("Error: the block " + strconv.FormatInt(next, 10) + " has not been committed, " + err.Error())
+		}
+		time.Sleep(time.Second)
+	}
+	if !bytes.Equal(commit.Header.Hash(), commit.Commit.BlockID.Hash) {
+		return errors.New("Error: the header does not match with the block of the commit.")
+	}
+
+	vals, err := cli.Validators(&next)
+	if err != nil {
+		return errors.New("Error: could not get the validators, " + err.Error())
+	}
+	if commit.ChainID != chainID {
+		return errors.New("Error: the commit is from the chain " + commit.ChainID + ", not from the chain " + chainID + ".")
+	}
+	vset := types.NewValidatorSet(vals.Validators)
+	if !bytes.Equal(vset.Hash(), commit.Header.ValidatorsHash) {
+		return errors.New("Error: the validators do not match with the header.")
+	}
+	err = vset.VerifyCommit(chainID, commit.Commit.BlockID, next, commit.Commit)
+	if err != nil {
+		return errors.New("Error: the commit has not been signed by the validators, " + err.Error())
+	}
+	// the validators have changed since the trusted validators,
+	// so more than two thirds of the trusted power need to have signed the commit too
+	if !bytes.Equal(vset.Hash(), trusted.Hash()) {
+		err = trusted.VerifyCommitAny(vset, chainID, commit.Commit.BlockID, next, commit.Commit)
+		if err != nil {
+			return errors.New("Error: the commit has not been signed by the trusted validators, update the file of TRUSTED_VALIDATORS with the later validators, " + err.Error())
+		}
+	}
+
+	for _, kp := range kps {
+		if kp.Proof == nil {
+			return errors.New("Error: the proof of the key " + string(kp.Key) + " is missing.")
+		}
+		err = kp.Proof.Verify(commit.Header.AppHash)
+		if err != nil {
+			return errors.New("Error: the proof does not match with the app hash, " + err.Error())
+		}
+		err = kp.Proof.VerifyItem(kp.Key, kp.Value)
+		if err != nil {
+			return errors.New("Error: the proof of the key " + string(kp.Key) + " is not correct, " + err.Error())
+		}
+	}
+	return nil
+}
+
+// provenValue returns the value of the key from the proofs
+func provenValue(kps []dbpkg.KeyProof, key []byte) ([]byte, error) {
+	for _, kp := range kps {
+		if bytes.Equal(kp.Key, key) {
+			return kp.Value, nil
+		}
+	}
+	return nil, errors.New("Error: the key " + string(key) + " has not been proven.")
+}
+
+// provenModel checks that the model of the query is the same with the model from the proven value
+func provenModel(resp *abci.ResponseQuery, model interface{}) error {
+	b, _ := json.Marshal(model)
+	if !bytes.Equal(b, resp.Value) {
+		return errors.New("Error: the response of the query does not match with its proof.")
+	}
+	return nil
+}
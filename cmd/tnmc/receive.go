@@ -16,8 +16,11 @@ import (
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 )
 
-func receive(vault, hash, secret string) ([]string, error) {
-	qmt, err := getTransaction(hash)
+// receive claims the coins of the transaction, the secret is either given by the sender
+// or decrypted from the transaction with the receiver's key.
+// When coins is empty, all the coins that have not been received yet are claimed.
+func receive(vault, hash, secret, key string, coins []string) ([]string, error) {
+	qmt, err := getTransaction(hash, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -25,9 +28,31 @@ func receive(vault, hash, secret string) ([]string, error) {
 		return nil, errors.New("Error: The coins has already been received.")
 	}
 
-	b, err := base64.RawStdEncoding.DecodeString(secret)
-	if err != nil {
-		return nil, errors.New("Error: The secret has problem with base64 encoding, " + err.Error())
+	var receiverPriv kyber.Scalar
+	if len(key) > 0 {
+		receiverPriv, err = readReceiverKey(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var b []byte
+	if len(secret) > 0 {
+		b, err = base64.RawStdEncoding.DecodeString(secret)
+		if err != nil {
+			return nil, errors.New("Error: The secret has problem with base64 encoding, " + err.Error())
+		}
+	} else {
+		if len(qmt.EncryptedSecret) == 0 {
+			return nil, errors.New("Error: The transaction does not have an encrypted secret.")
+		}
+		if receiverPriv == nil {
+			return nil, errors.New("Error: The key is needed to decrypt the secret of the transaction.")
+		}
+		b, err = utils.Decrypt(receiverPriv, qmt.EncryptedSecret)
+		if err != nil {
+			return nil, errors.New("Error: The secret could not be decrypted with the key, " + err.Error())
+		}
 	}
 	pv := models.ProofVerification{}
 	err = json.Unmarshal(b, &pv)
@@ -35,10 +60,41 @@ func receive(vault, hash, secret string) ([]string, error) {
 		return nil, errors.New("Error: The secret has problem with json encoding, " + err.Error())
 	}
 
+	received := map[string]bool{}
+	for _, coin := range qmt.ReceivedCoins {
+		received[coin] = true
+	}
+	if len(coins) == 0 {
+		for _, coin := range qmt.Coins {
+			if !received[coin] {
+				coins = append(coins, coin)
+			}
+		}
+	}
+	for _, coin := range coins {
+		isFound := false
+		for _, trCoin := range qmt.Coins {
+			if trCoin == coin {
+				isFound = true
+				break
+			}
+		}
+		if !isFound {
+			return nil, errors.New("Error: The coin " + coin + " is not in the transaction.")
+		}
+		if received[coin] {
+			return nil, errors.New("Error: The coin " + coin + " has already been received.")
+		}
+	}
+
+	if len(qmt.Recipient) == 0 && len(coins) != len(qmt.Coins) {
+		return nil, errors.New("Error: The transaction does not have a recipient, all its coins need to be received together.")
+	}
+
 	newOwnerPubPerCoin := map[string]string{}
 	newOwnersPrivHexPerCoin := map[string]string{}
 	newOwnersPrivs := []kyber.Scalar{}
-	for _, coin := range qmt.Coins {
+	for _, coin := range coins {
 		newOwnerKp, newOwnerPubHex := utils.CreateKeyPair()
 		newOwnerPubPerCoin[coin] = newOwnerPubHex
 		privB, _ := newOwnerKp.Private.MarshalBinary()
@@ -46,6 +102,11 @@ func receive(vault, hash, secret string) ([]string, error) {
 		newOwnersPrivHexPerCoin[coin] = newOwnerPrivHex
 		newOwnersPrivs = append(newOwnersPrivs, newOwnerKp.Private)
 	}
+	if len(qmt.Recipient) > 0 {
+		if receiverPriv == nil {
+			return nil, errors.New("Error: The transaction is for the recipient " + qmt.Recipient + ", the key of the recipient is needed.")
+		}
+	}
 
 	data := models.ReceiveData{}
 	data.NewOwners = newOwnerPubPerCoin
@@ -54,24 +115,30 @@ func receive(vault, hash, secret string) ([]string, error) {
 
 	d := models.Delivery{}
 	d.Type = models.RECEIVE
-	d.Data = data
-	msg, _ := json.Marshal(data)
+	msg, err := signBytes(d.Type, data)
+	if err != nil {
+		return nil, err
+	}
 	d.Signature, _ = utils.MultiSignature(newOwnersPrivs, msg)
+	if len(qmt.Recipient) > 0 {
+		data.RecipientSignature, _ = utils.Sign(receiverPriv, msg)
+	}
+	d.Data = data
 
-	dB, _ := json.Marshal(d)
+	dB, _ := models.EncodeDelivery(d)
 	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
 	btc, err := cli.BroadcastTxCommit(types.Tx(dB))
 	if err != nil {
 		return nil, errors.New("Error: " + err.Error())
 	}
-	if btc.CheckTx.Code > models.CodeTypeOK {
-		return nil, errors.New("Error: " + btc.CheckTx.Log)
+	if err := broadcastError(btc); err != nil {
+		return nil, err
 	}
 
 	filenames := []string{}
 	for coin, pub := range newOwnerPubPerCoin {
 		priv := newOwnersPrivHexPerCoin[coin]
-		qmc, err := getCoin(coin)
+		qmc, err := getCoin(coin, 0)
 		if err != nil {
 			fmt.Println("Error: The coin " + coin + " disappeared from the system: " + err.Error())
 			fmt.Println("The public key of the coin: " + pub)
@@ -81,6 +148,7 @@ func receive(vault, hash, secret string) ([]string, error) {
 		cj := CoinJson{}
 		cj.UUID = coin
 		cj.Value = qmc.Value
+		cj.Asset = qmc.Asset
 		cj.OwnerPublicKey = pub
 		cj.OwnerPrivateKey = priv
 
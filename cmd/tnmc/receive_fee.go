@@ -22,7 +22,7 @@ func receiveFee(inflatorKpj KeyPairJson, vault, hash string) ([]string, error) {
 	inflatorPrivateKey := suite.Scalar()
 	inflatorPrivateKey.UnmarshalBinary(inflatorPrivateKeyB)
 
-	qmt, err := getTransaction(hash)
+	qmt, err := getTransaction(hash, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -50,23 +50,26 @@ func receiveFee(inflatorKpj KeyPairJson, vault, hash string) ([]string, error) {
 	d := models.Delivery{}
 	d.Type = models.RETRIEVE_FEE
 	d.Data = data
-	msg, _ := json.Marshal(data)
+	msg, err := signBytes(d.Type, data)
+	if err != nil {
+		return nil, err
+	}
 	d.Signature, _ = utils.MultiSignature(privs, msg)
 
-	dB, _ := json.Marshal(d)
+	dB, _ := models.EncodeDelivery(d)
 	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
 	btc, err := cli.BroadcastTxCommit(types.Tx(dB))
 	if err != nil {
 		return nil, errors.New("Error: " + err.Error())
 	}
-	if btc.CheckTx.Code > models.CodeTypeOK {
-		return nil, errors.New("Error: " + btc.CheckTx.Log)
+	if err := broadcastError(btc); err != nil {
+		return nil, err
 	}
 
 	filenames := []string{}
 	for coin, pub := range newOwnerPubPerCoin {
 		priv := newOwnersPrivHexPerCoin[coin]
-		qmc, err := getCoin(coin)
+		qmc, err := getCoin(coin, 0)
 		if err != nil {
 			fmt.Println("Error: The coin " + coin + " disappeared from the system: " + err.Error())
 			fmt.Println("The public key of the coin: " + pub)
@@ -76,6 +79,7 @@ func receiveFee(inflatorKpj KeyPairJson, vault, hash string) ([]string, error) {
 		cj := CoinJson{}
 		cj.UUID = coin
 		cj.Value = qmc.Value
+		cj.Asset = qmc.Asset
 		cj.OwnerPublicKey = pub
 		cj.OwnerPrivateKey = priv
 
@@ -7,12 +7,14 @@ import (
 	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"net/url"
 	"os"
 
 	"github.com/dedis/kyber"
 	client "github.com/tendermint/tendermint/rpc/client"
 	"github.com/tendermint/tendermint/types"
 
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
 	"github.com/mragiadakos/tendermoney/app/ctrls/query"
 	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
 
@@ -23,7 +25,16 @@ import (
 	"github.com/mragiadakos/tendermoney/app/ctrls/models"
 )
 
-func send(coins, fee []string, vault string) (string, string, error) {
+// the optional parts of a send
+type sendOptions struct {
+	Memo           string
+	MemoKey        string // the receiver's public key to encrypt the memo
+	SecretKey      string // the receiver's public key to encrypt the secret
+	Recipient      string
+	StealthAddress string
+}
+
+func send(coins, fee []string, vault string, opts sendOptions) (string, string, error) {
 	cjs := []CoinJson{} // the json of the coins
 	for _, coin := range coins {
 		coinFile, err := ioutil.ReadFile(vault + "/" + coin)
@@ -55,27 +66,56 @@ func send(coins, fee []string, vault string) (string, string, error) {
 	data := models.SendData{}
 	data.Coins = coins
 	data.Fee = fee
-	rng := random.New()
-	suite := edwards25519.NewBlakeSHA256Ed25519()
-
-	// Create some random secrets and base points
-	x := suite.Scalar().Pick(rng)
-	g := suite.Point().Pick(rng)
-	h := suite.Point().Pick(rng)
-
-	proof, xG, xH, err := dleq.NewDLEQProof(suite, g, h, x)
-	if err != nil {
-		return "", "", errors.New("Error: failed to create a proof:" + err.Error())
+	data.Memo = opts.Memo
+	data.Recipient = opts.Recipient
+	if len(opts.MemoKey) > 0 {
+		encryptedMemo, err := utils.Encrypt(opts.MemoKey, []byte(opts.Memo))
+		if err != nil {
+			return "", "", errors.New("Error: failed to encrypt the memo: " + err.Error())
+		}
+		data.Memo = ""
+		data.EncryptedMemo = encryptedMemo
 	}
-	data.Proof = models.NewProof(proof)
+	suite := edwards25519.NewBlakeSHA256Ed25519()
 
 	msg, _ := json.Marshal(coins)
 	hash := sha256.Sum256(msg)
 	hashHex := hex.EncodeToString(hash[:])
 
-	proofVerification := models.NewProofVerification(g, h, xG, xH)
-	proofVerificationB, _ := json.Marshal(proofVerification)
-	secret := base64.StdEncoding.EncodeToString(proofVerificationB)
+	secret := ""
+	if len(opts.StealthAddress) > 0 {
+		// the coins go directly to the one-time owners, there is no secret
+		stealthR, stealthOwners, err := utils.StealthOwners(opts.StealthAddress, coins)
+		if err != nil {
+			return "", "", errors.New("Error: " + err.Error())
+		}
+		data.StealthR = stealthR
+		data.StealthOwners = stealthOwners
+	} else {
+		rng := random.New()
+
+		// Create some random secrets and base points
+		x := suite.Scalar().Pick(rng)
+		g := suite.Point().Pick(rng)
+		h := suite.Point().Pick(rng)
+
+		proof, xG, xH, err := dleq.NewDLEQProof(suite, g, h, x)
+		if err != nil {
+			return "", "", errors.New("Error: failed to create a proof:" + err.Error())
+		}
+		data.Proof = models.NewProof(proof)
+
+		proofVerification := models.NewProofVerification(g, h, xG, xH)
+		proofVerificationB, _ := json.Marshal(proofVerification)
+		secret = base64.StdEncoding.EncodeToString(proofVerificationB)
+		if len(opts.SecretKey) > 0 {
+			encryptedSecret, err := utils.Encrypt(opts.SecretKey, proofVerificationB)
+			if err != nil {
+				return "", "", errors.New("Error: failed to encrypt the secret: " + err.Error())
+			}
+			data.EncryptedSecret = encryptedSecret
+		}
+	}
 
 	allCjs := append(cjs, fjs...)
 	privks := []kyber.Scalar{}
@@ -95,17 +135,20 @@ func send(coins, fee []string, vault string) (string, string, error) {
 	d := models.Delivery{}
 	d.Type = models.SEND
 	d.Data = data
-	dataB, _ := json.Marshal(data)
+	dataB, err := signBytes(d.Type, data)
+	if err != nil {
+		return "", "", err
+	}
 	d.Signature, _ = utils.MultiSignature(privks, dataB)
 
-	dB, _ := json.Marshal(d)
+	dB, _ := models.EncodeDelivery(d)
 	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
 	btc, err := cli.BroadcastTxCommit(types.Tx(dB))
 	if err != nil {
 		return "", "", errors.New("Error: " + err.Error())
 	}
-	if btc.CheckTx.Code > models.CodeTypeOK {
-		return "", "", errors.New("Error: " + btc.CheckTx.Log)
+	if err := broadcastError(btc); err != nil {
+		return "", "", err
 	}
 
 	// remove all the coins
@@ -116,34 +159,102 @@ func send(coins, fee []string, vault string) (string, string, error) {
 	return hashHex, secret, nil
 }
 
-func getTransaction(hash string) (*query.QueryModelTransaction, error) {
-	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
-	q, err := cli.ABCIQuery("get_transaction?hash="+hash, nil)
+func getTransaction(hash string, height int64) (*query.QueryModelTransaction, error) {
+	resp, kps, err := provenQuery("get_transaction?hash="+hash, height)
 	if err != nil {
-		return nil, errors.New("Error:" + err.Error())
-	}
-	if q.Response.Code > models.CodeTypeOK {
-		return nil, errors.New("Error: " + q.Response.Log)
+		return nil, err
 	}
 
 	qmt := query.QueryModelTransaction{}
-	json.Unmarshal(q.Response.Value, &qmt)
+	json.Unmarshal(resp.Value, &qmt)
+	if Confs.TrustNode {
+		return &qmt, nil
+	}
+	v, err := provenValue(kps, dbpkg.TransactionKey(hash))
+	if err != nil {
+		return nil, err
+	}
+	st := dbpkg.StateTransaction{}
+	json.Unmarshal(v, &st)
+	err = provenModel(resp, query.NewQueryModelTransaction(hash, &st))
+	if err != nil {
+		return nil, err
+	}
 	return &qmt, nil
 }
 
-func getCoin(uuid string) (*query.QueryModelCoin, error) {
+func readReceiverKey(key string) (kyber.Scalar, error) {
+	keyB, err := ioutil.ReadFile(key)
+	if err != nil {
+		return nil, errors.New("Error: could not read the file that contains the key of the receiver, " + err.Error())
+	}
+	kpj := KeyPairJson{}
+	err = json.Unmarshal(keyB, &kpj)
+	if err != nil {
+		return nil, errors.New("Error: could not read the json format that contains the key of the receiver, " + err.Error())
+	}
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	privB, err := hex.DecodeString(kpj.PrivateKey)
+	if err != nil {
+		return nil, errors.New("Error: The private key of the receiver is not a correct hexadecimal format.")
+	}
+	priv := suite.Scalar()
+	err = priv.UnmarshalBinary(privB)
+	if err != nil {
+		return nil, errors.New("Error: The private key of the receiver is not correct.")
+	}
+	return priv, nil
+}
+
+func decryptMemo(key, encryptedMemo string) (string, error) {
+	priv, err := readReceiverKey(key)
+	if err != nil {
+		return "", err
+	}
+	memo, err := utils.Decrypt(priv, encryptedMemo)
+	if err != nil {
+		return "", errors.New("Error: failed to decrypt the memo: " + err.Error())
+	}
+	return string(memo), nil
+}
+
+func getCoin(uuid string, height int64) (*query.QueryModelCoin, error) {
+	resp, kps, err := provenQuery("get_coin?coin="+uuid, height)
+	if err != nil {
+		return nil, err
+	}
+
+	qmc := query.QueryModelCoin{}
+	json.Unmarshal(resp.Value, &qmc)
+	if Confs.TrustNode {
+		return &qmc, nil
+	}
+	v, err := provenValue(kps, dbpkg.CoinKey(uuid))
+	if err != nil {
+		return nil, err
+	}
+	sc := dbpkg.StateCoin{}
+	json.Unmarshal(v, &sc)
+	err = provenModel(resp, query.NewQueryModelCoin(uuid, &sc))
+	if err != nil {
+		return nil, err
+	}
+	return &qmc, nil
+}
+
+func getCoinHistory(uuid string) ([]query.QueryModelCoinHistory, error) {
 	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
-	q, err := cli.ABCIQuery("get_coin?coin="+uuid, nil)
+	q, err := cli.ABCIQuery("get_coin_history?coin="+uuid, nil)
 	if err != nil {
 		return nil, errors.New("Error:" + err.Error())
 	}
 	if q.Response.Code > models.CodeTypeOK {
-		return nil, errors.New("Error: " + q.Response.Log)
+		return nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
 	}
 
-	qmc := query.QueryModelCoin{}
-	json.Unmarshal(q.Response.Value, &qmc)
-	return &qmc, nil
+	qmchs := []query.QueryModelCoinHistory{}
+	json.Unmarshal(q.Response.Value, &qmchs)
+	return qmchs, nil
 }
 
 func getTransactionsWithUnreceivedFee(hash string) ([]query.QueryModelTransaction, error) {
@@ -153,10 +264,32 @@ func getTransactionsWithUnreceivedFee(hash string) ([]query.QueryModelTransactio
 		return nil, errors.New("Error:" + err.Error())
 	}
 	if q.Response.Code > models.CodeTypeOK {
-		return nil, errors.New("Error: " + q.Response.Log)
+		return nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
 	}
 
 	qmts := []query.QueryModelTransaction{}
 	json.Unmarshal(q.Response.Value, &qmts)
 	return qmts, nil
 }
+
+func listTransactions(filters url.Values, after string) (*query.QueryModelTransactionList, error) {
+	values := url.Values{}
+	for k, v := range filters {
+		values[k] = v
+	}
+	if len(after) > 0 {
+		values.Set("after", after)
+	}
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+	q, err := cli.ABCIQuery("list_transactions?"+values.Encode(), nil)
+	if err != nil {
+		return nil, errors.New("Error:" + err.Error())
+	}
+	if q.Response.Code > models.CodeTypeOK {
+		return nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
+	}
+
+	qmtl := query.QueryModelTransactionList{}
+	json.Unmarshal(q.Response.Value, &qmtl)
+	return &qmtl, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/query"
+	client "github.com/tendermint/tendermint/rpc/client"
+)
+
+func listSlashes(validator string) ([]query.QueryModelSlash, error) {
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+	path := "list_slashes"
+	if len(validator) > 0 {
+		path += "?validator=" + url.QueryEscape(validator)
+	}
+	q, err := cli.ABCIQuery(path, nil)
+	if err != nil {
+		return nil, errors.New("Error:" + err.Error())
+	}
+	if q.Response.Code > models.CodeTypeOK {
+		return nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
+	}
+	qmss := []query.QueryModelSlash{}
+	json.Unmarshal(q.Response.Value, &qmss)
+	return qmss, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+
+	"github.com/dedis/kyber"
+	"github.com/dedis/kyber/group/edwards25519"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/query"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	client "github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tendermint/types"
+)
+
+// coinsPrivateKeys returns the private keys of the owners of the vault's coins
+func coinsPrivateKeys(vault string, coins []string) ([]kyber.Scalar, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	privks := []kyber.Scalar{}
+	for _, coin := range coins {
+		cj, err := readCoin(vault, coin)
+		if err != nil {
+			return nil, err
+		}
+		privB, err := hex.DecodeString(cj.OwnerPrivateKey)
+		if err != nil {
+			return nil, errors.New("Error: The private key from the owner of the coin " + cj.UUID + ", is not a correct hexadecimal format.")
+		}
+		priv := suite.Scalar()
+		err = priv.UnmarshalBinary(privB)
+		if err != nil {
+			return nil, errors.New("Error: The private key from the owner of the coin " + cj.UUID + ", is not correct.")
+		}
+		privks = append(privks, priv)
+	}
+	return privks, nil
+}
+
+// broadcastSigned signs the data with the private keys and broadcasts the delivery
+func broadcastSigned(t models.DeliveryType, data interface{}, privks []kyber.Scalar) error {
+	d := models.Delivery{}
+	d.Type = t
+	d.Data = data
+	msg, err := signBytes(d.Type, data)
+	if err != nil {
+		return err
+	}
+	d.Signature, _ = utils.MultiSignature(privks, msg)
+
+	dB, _ := models.EncodeDelivery(d)
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+	btc, err := cli.BroadcastTxCommit(types.Tx(dB))
+	if err != nil {
+		return errors.New("Error: " + err.Error())
+	}
+	return broadcastError(btc)
+}
+
+// stake locks the coins of the vault to back the voting power of the validator,
+// the coins stay in the vault because they have the same owner
+func stake(vault string, coins []string, validator string) error {
+	privks, err := coinsPrivateKeys(vault, coins)
+	if err != nil {
+		return err
+	}
+	data := models.StakeData{}
+	data.Coins = coins
+	data.Validator = validator
+	return broadcastSigned(models.STAKE, data, privks)
+}
+
+// unstake starts the unbonding of the staked coins of the vault
+func unstake(vault string, coins []string) error {
+	privks, err := coinsPrivateKeys(vault, coins)
+	if err != nil {
+		return err
+	}
+	data := models.UnstakeData{}
+	data.Coins = coins
+	return broadcastSigned(models.UNSTAKE, data, privks)
+}
+
+func getStakes(validator string) ([]query.QueryModelStake, error) {
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+	q, err := cli.ABCIQuery("get_stakes?validator="+url.QueryEscape(validator), nil)
+	if err != nil {
+		return nil, errors.New("Error:" + err.Error())
+	}
+	if q.Response.Code > models.CodeTypeOK {
+		return nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
+	}
+	qmss := []query.QueryModelStake{}
+	json.Unmarshal(q.Response.Value, &qmss)
+	return qmss, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/dedis/kyber"
+	"github.com/dedis/kyber/group/edwards25519"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/query"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	client "github.com/tendermint/tendermint/rpc/client"
+)
+
+func generateStealth(filename string) (string, error) {
+	viewKp, spendKp, address := utils.CreateStealthAddress()
+	skj := StealthKeyJson{}
+	skj.Address = address
+	viewPubB, _ := viewKp.Public.MarshalBinary()
+	skj.ViewPublicKey = hex.EncodeToString(viewPubB)
+	viewPrivB, _ := viewKp.Private.MarshalBinary()
+	skj.ViewPrivateKey = hex.EncodeToString(viewPrivB)
+	spendPubB, _ := spendKp.Public.MarshalBinary()
+	skj.SpendPublicKey = hex.EncodeToString(spendPubB)
+	spendPrivB, _ := spendKp.Private.MarshalBinary()
+	skj.SpendPrivateKey = hex.EncodeToString(spendPrivB)
+	b, _ := json.Marshal(skj)
+	err := ioutil.WriteFile(filename, b, 0644)
+	if err != nil {
+		return "", errors.New("Error: " + err.Error())
+	}
+	return address, nil
+}
+
+func getStealthTransactions() ([]query.QueryModelTransaction, error) {
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+	q, err := cli.ABCIQuery("get_stealth_transactions", nil)
+	if err != nil {
+		return nil, errors.New("Error:" + err.Error())
+	}
+	if q.Response.Code > models.CodeTypeOK {
+		return nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
+	}
+
+	qmts := []query.QueryModelTransaction{}
+	json.Unmarshal(q.Response.Value, &qmts)
+	return qmts, nil
+}
+
+func unmarshalPrivateKey(privHex string) (kyber.Scalar, error) {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	privB, err := hex.DecodeString(privHex)
+	if err != nil {
+		return nil, errors.New("Error: The private key is not a correct hexadecimal format.")
+	}
+	priv := suite.Scalar()
+	err = priv.UnmarshalBinary(privB)
+	if err != nil {
+		return nil, errors.New("Error: The private key is not correct.")
+	}
+	return priv, nil
+}
+
+// scan finds the coins of the stealth address that are still owned by their one-time owners
+// and saves them in the vault
+func scan(key, vault string) ([]string, error) {
+	keyB, err := ioutil.ReadFile(key)
+	if err != nil {
+		return nil, errors.New("Error: could not read the file that contains the stealth key, " + err.Error())
+	}
+	skj := StealthKeyJson{}
+	err = json.Unmarshal(keyB, &skj)
+	if err != nil {
+		return nil, errors.New("Error: could not read the json format that contains the stealth key, " + err.Error())
+	}
+	viewPriv, err := unmarshalPrivateKey(skj.ViewPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	spendPriv, err := unmarshalPrivateKey(skj.SpendPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	qmts, err := getStealthTransactions()
+	if err != nil {
+		return nil, err
+	}
+	filenames := []string{}
+	for _, qmt := range qmts {
+		for coin, owner := range qmt.StealthOwners {
+			filename := vault + "/" + coin
+			if _, err := os.Stat(filename); err == nil {
+				continue
+			}
+			priv, isMine, err := utils.StealthPrivateKey(viewPriv, spendPriv, qmt.StealthR, coin, owner)
+			if err != nil || !isMine {
+				continue
+			}
+			// the coin could have been spent already
+			qmc, err := getCoin(coin, 0)
+			if err != nil || qmc.Owner != owner {
+				continue
+			}
+			cj := CoinJson{}
+			cj.UUID = coin
+			cj.Value = qmc.Value
+			cj.Asset = qmc.Asset
+			cj.OwnerPublicKey = owner
+			privB, _ := priv.MarshalBinary()
+			cj.OwnerPrivateKey = hex.EncodeToString(privB)
+			coinFileB, _ := json.Marshal(cj)
+			err = ioutil.WriteFile(filename, coinFileB, 0644)
+			if err != nil {
+				return filenames, errors.New("Error: The coin " + coin + " failed to be written in a file: " + err.Error())
+			}
+			filenames = append(filenames, filename)
+		}
+	}
+	return filenames, nil
+}
@@ -16,7 +16,7 @@ import (
 	"github.com/tendermint/tendermint/types"
 )
 
-func sum(coins []string, vault string) (string, error) {
+func sum(coins, fee []string, vault string) (string, error) {
 	cjs := []CoinJson{}
 	for _, coin := range coins {
 		coinFile, err := ioutil.ReadFile(vault + "/" + coin)
@@ -37,6 +37,7 @@ func sum(coins []string, vault string) (string, error) {
 	data.Coins = coins
 	data.NewCoin = uuid.NewV4().String()
 	data.NewOwner = newOwnerPubHex
+	data.Fee = fee
 
 	suite := edwards25519.NewBlakeSHA256Ed25519()
 	privks := []kyber.Scalar{newOwnerKp.Private}
@@ -54,26 +55,35 @@ func sum(coins []string, vault string) (string, error) {
 		privks = append(privks, priv)
 		sumNumber += cj.Value
 	}
+	feePrivks, err := coinsPrivateKeys(vault, fee)
+	if err != nil {
+		return "", err
+	}
+	privks = append(privks, feePrivks...)
 
 	d := models.Delivery{}
 	d.Type = models.SUM
 	d.Data = data
-	dataB, _ := json.Marshal(data)
+	dataB, err := signBytes(d.Type, data)
+	if err != nil {
+		return "", err
+	}
 	d.Signature, _ = utils.MultiSignature(privks, dataB)
-	dB, _ := json.Marshal(d)
+	dB, _ := models.EncodeDelivery(d)
 	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
 	btc, err := cli.BroadcastTxCommit(types.Tx(dB))
 	if err != nil {
 		return "", errors.New("Error: " + err.Error())
 	}
-	if btc.CheckTx.Code > models.CodeTypeOK {
-		return "", errors.New("Error: " + btc.CheckTx.Log)
+	if err := broadcastError(btc); err != nil {
+		return "", err
 	}
 
 	// after the success, we save the coin in the vault
 	newCoin := CoinJson{}
 	newCoin.UUID = data.NewCoin
 	newCoin.Value = sumNumber
+	newCoin.Asset = cjs[0].Asset
 	newOwnerPrivB, _ := newOwnerKp.Private.MarshalBinary()
 	newOwnerPrivHex := hex.EncodeToString(newOwnerPrivB)
 	newCoin.OwnerPrivateKey = newOwnerPrivHex
@@ -86,8 +96,8 @@ func sum(coins []string, vault string) (string, error) {
 		return "", errors.New("Error: " + err.Error())
 	}
 
-	// remove the old ones
-	for _, coin := range coins {
+	// remove the old ones and the fee
+	for _, coin := range append(coins, fee...) {
 		os.Remove(vault + "/" + coin)
 	}
 
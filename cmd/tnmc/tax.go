@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"net/url"
 
 	"github.com/dedis/kyber"
 	"github.com/dedis/kyber/group/edwards25519"
@@ -14,7 +15,7 @@ import (
 	"github.com/tendermint/tendermint/types"
 )
 
-func tax(inflatorKpj KeyPairJson, percent int) error {
+func tax(inflatorKpj KeyPairJson, percent int, asset string, demurrage int) error {
 	suite := edwards25519.NewBlakeSHA256Ed25519()
 	inflatorPrivateKeyB, _ := hex.DecodeString(inflatorKpj.PrivateKey)
 	inflatorPrivateKey := suite.Scalar()
@@ -23,14 +24,19 @@ func tax(inflatorKpj KeyPairJson, percent int) error {
 	data := models.TaxData{}
 	data.Inflator = inflatorKpj.PublicKey
 	data.Percentage = percent
+	data.Asset = asset
+	data.Demurrage = demurrage
 
 	d := models.Delivery{}
 	d.Data = data
 	d.Type = models.TAX
-	msg, _ := json.Marshal(data)
+	msg, err := signBytes(d.Type, data)
+	if err != nil {
+		return err
+	}
 	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{inflatorPrivateKey}, msg)
 
-	dB, _ := json.Marshal(d)
+	dB, _ := models.EncodeDelivery(d)
 
 	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
 
@@ -38,21 +44,21 @@ func tax(inflatorKpj KeyPairJson, percent int) error {
 	if err != nil {
 		return errors.New("Error: " + err.Error())
 	}
-	if btc.CheckTx.Code > models.CodeTypeOK {
-		return errors.New("Error: " + btc.CheckTx.Log)
+	if err := broadcastError(btc); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func getLatestTax() (*query.QueryModelTax, error) {
+func getLatestTax(height int64, asset string) (*query.QueryModelTax, error) {
 	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
-	q, err := cli.ABCIQuery("get_latest_tax", nil)
+	q, err := cli.ABCIQueryWithOptions("get_latest_tax?asset="+url.QueryEscape(asset), nil, client.ABCIQueryOptions{Height: height, Trusted: true})
 	if err != nil {
 		return nil, errors.New("Error:" + err.Error())
 	}
 	if q.Response.Code > models.CodeTypeOK {
-		return nil, errors.New("Error: " + q.Response.Log)
+		return nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
 	}
 	qmt := query.QueryModelTax{}
 	json.Unmarshal(q.Response.Value, &qmt)
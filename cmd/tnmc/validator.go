@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/dedis/kyber"
+	"github.com/dedis/kyber/group/edwards25519"
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/query"
+	"github.com/mragiadakos/tendermoney/app/ctrls/utils"
+	client "github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tendermint/types"
+)
+
+func setValidator(inflatorKpj KeyPairJson, pubKey string, power int64) error {
+	suite := edwards25519.NewBlakeSHA256Ed25519()
+	inflatorPrivateKeyB, _ := hex.DecodeString(inflatorKpj.PrivateKey)
+	inflatorPrivateKey := suite.Scalar()
+	inflatorPrivateKey.UnmarshalBinary(inflatorPrivateKeyB)
+
+	data := models.ValidatorData{}
+	data.PubKey = pubKey
+	data.Power = power
+	data.Inflator = inflatorKpj.PublicKey
+
+	d := models.Delivery{}
+	d.Data = data
+	d.Type = models.SET_VALIDATOR
+	msg, err := signBytes(d.Type, data)
+	if err != nil {
+		return err
+	}
+	d.Signature, _ = utils.MultiSignature([]kyber.Scalar{inflatorPrivateKey}, msg)
+
+	dB, _ := models.EncodeDelivery(d)
+
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+
+	btc, err := cli.BroadcastTxCommit(types.Tx(dB))
+	if err != nil {
+		return errors.New("Error: " + err.Error())
+	}
+	if err := broadcastError(btc); err != nil {
+		return err
+	}
+	return nil
+}
+
+func listValidators() ([]query.QueryModelValidator, error) {
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+	q, err := cli.ABCIQuery("list_validators", nil)
+	if err != nil {
+		return nil, errors.New("Error:" + err.Error())
+	}
+	if q.Response.Code > models.CodeTypeOK {
+		return nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
+	}
+	qmvs := []query.QueryModelValidator{}
+	json.Unmarshal(q.Response.Value, &qmvs)
+	return qmvs, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strings"
+
+	client "github.com/tendermint/tendermint/rpc/client"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls/models"
+	"github.com/mragiadakos/tendermoney/app/ctrls/query"
+)
+
+// The statuses of the vault's coins
+const (
+	COIN_STATUS_OK            = "ok"
+	COIN_STATUS_LOCKED        = "locked"
+	COIN_STATUS_NOT_FOUND     = "not found"
+	COIN_STATUS_OWNER_CHANGED = "owner changed"
+)
+
+type coinStatus struct {
+	CoinJson
+	Status string
+}
+
+// readVault returns the coins of the vault, the other files are skipped
+func readVault(vault string) ([]CoinJson, error) {
+	files, err := ioutil.ReadDir(vault)
+	if err != nil {
+		return nil, errors.New("Error: could not read the vault, " + err.Error())
+	}
+	cjs := []CoinJson{}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		b, err := ioutil.ReadFile(vault + "/" + f.Name())
+		if err != nil {
+			continue
+		}
+		cj := CoinJson{}
+		err = json.Unmarshal(b, &cj)
+		if err != nil || len(cj.UUID) == 0 {
+			continue
+		}
+		cjs = append(cjs, cj)
+	}
+	return cjs, nil
+}
+
+// readCoin returns the coin of the vault
+func readCoin(vault, coin string) (*CoinJson, error) {
+	b, err := ioutil.ReadFile(vault + "/" + coin)
+	if err != nil {
+		return nil, errors.New("Error: The file for the coin " + coin + " is missing.")
+	}
+	cj := CoinJson{}
+	err = json.Unmarshal(b, &cj)
+	if err != nil {
+		return nil, errors.New("Error: The file for the coin " + coin + " does not unmarshal: " + err.Error())
+	}
+	return &cj, nil
+}
+
+func getCoins(uuids []string) ([]query.QueryModelCoinLookup, error) {
+	cli := client.NewHTTP(Confs.TendermintNode, "/websocket")
+	q, err := cli.ABCIQuery("get_coins?coins="+strings.Join(uuids, ","), nil)
+	if err != nil {
+		return nil, errors.New("Error:" + err.Error())
+	}
+	if q.Response.Code > models.CodeTypeOK {
+		return nil, codeError(q.Response.Codespace, q.Response.Code, q.Response.Log)
+	}
+
+	qmcls := []query.QueryModelCoinLookup{}
+	json.Unmarshal(q.Response.Value, &qmcls)
+	return qmcls, nil
+}
+
+// vaultStatus looks up the coins of the vault in batches
+func vaultStatus(vault string) ([]coinStatus, error) {
+	cjs, err := readVault(vault)
+	if err != nil {
+		return nil, err
+	}
+	css := []coinStatus{}
+	for start := 0; start < len(cjs); start += query.GET_COINS_MAX {
+		end := start + query.GET_COINS_MAX
+		if end > len(cjs) {
+			end = len(cjs)
+		}
+		uuids := []string{}
+		for _, cj := range cjs[start:end] {
+			uuids = append(uuids, cj.UUID)
+		}
+		qmcls, err := getCoins(uuids)
+		if err != nil {
+			return nil, err
+		}
+		if len(qmcls) != len(uuids) {
+			return nil, errors.New("Error: the node did not answer for all the coins.")
+		}
+		for i, cj := range cjs[start:end] {
+			cs := coinStatus{CoinJson: cj, Status: COIN_STATUS_OK}
+			qmcl := qmcls[i]
+			switch {
+			case qmcl.NotFound || qmcl.Coin == nil:
+				cs.Status = COIN_STATUS_NOT_FOUND
+			case qmcl.Coin.Owner != cj.OwnerPublicKey:
+				cs.Status = COIN_STATUS_OWNER_CHANGED
+			case qmcl.Coin.IsLocked:
+				cs.Status = COIN_STATUS_LOCKED
+			}
+			css = append(css, cs)
+		}
+	}
+	return css, nil
+}
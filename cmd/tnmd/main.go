@@ -16,14 +16,34 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			exportCommand(os.Args[2:])
+			return
+		case "import":
+			importCommand(os.Args[2:])
+			return
+		}
+	}
+
 	logger := tmlog.NewTMLogger(kitlog.NewSyncWriter(os.Stdout))
 	flagAbci := "socket"
 	ipfsDaemon := flag.String("ipfs", "127.0.0.1:5001", "the URL for the IPFS's daemon")
 	node := flag.String("node", "tcp://0.0.0.0:26658", "the TCP URL for the ABCI daemon")
 	inflatorsHash := flag.String("inflators-hash", "", "the IPFS hash with the json for the inflators")
+	inflatorsRoot := flag.String("inflators-root", "", "the IPFS hash of the first version of the inflators that is trusted, needed when the inflators-hash is a later version")
+	contentDir := flag.String("content-dir", "", "the directory with the files of the hashes named by their hash, to use instead of IPFS")
 	inflatorsFile := flag.String("inflators-file", "", "the file with json array of public keys")
+	keepVersions := flag.Int64("keep-versions", 0, "how many of the latest heights are kept for the queries, zero keeps all of them")
+	dbDir := flag.String("db-dir", "", "the directory of the database for the state, without it the state is kept in the memory")
 	flag.Parse()
 
+	confs.Conf.IpfsConnection = *ipfsDaemon
+	confs.Conf.InflatorsRoot = *inflatorsRoot
+	if len(*contentDir) > 0 {
+		confs.Conf.Fetcher = confs.NewDirFetcher(*contentDir)
+	}
 	if len(*inflatorsHash) > 0 {
 
 		err := confs.Conf.SetInflatorsFromHash(*inflatorsHash)
@@ -50,7 +70,8 @@ func main() {
 		confs.Conf.Inflators = arr
 	}
 	confs.Conf.AbciDaemon = *node
-	confs.Conf.IpfsConnection = *ipfsDaemon
+	confs.Conf.KeepVersions = *keepVersions
+	confs.Conf.DBDir = *dbDir
 
 	app := ctrls.NewTMApplication()
 	srv, err := absrv.NewServer(confs.Conf.AbciDaemon, flagAbci, app)
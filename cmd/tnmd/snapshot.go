@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/mragiadakos/tendermoney/app/ctrls"
+	"github.com/mragiadakos/tendermoney/app/ctrls/dbpkg"
+	dbm "github.com/tendermint/tmlibs/db"
+)
+
+// exportCommand writes the snapshot of the state of a height to a file.
+// The daemon needs to be stopped, because it locks the database.
+func exportCommand(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dbDir := fs.String("db-dir", "", "the directory of the database for the state")
+	height := fs.Int64("height", 0, "the height to export, zero is the latest height")
+	file := fs.String("file", "snapshot.json", "the file for the snapshot")
+	inflatorsFile := fs.String("inflators-file", "", "the file with json array of public keys, that are added to the snapshot")
+	fs.Parse(args)
+
+	if len(*dbDir) == 0 {
+		fmt.Println("Error: need the directory of the database to continue.")
+		return
+	}
+	state := dbpkg.LoadState(dbm.NewDB("tendermoney", dbm.GoLevelDBBackend, *dbDir))
+	snap, err := state.Export(*height)
+	if err != nil {
+		fmt.Println("Error:", err.Error())
+		return
+	}
+	if len(*inflatorsFile) > 0 {
+		b, err := ioutil.ReadFile(*inflatorsFile)
+		if err != nil {
+			fmt.Println("Error: the file " + *inflatorsFile + " has problem:" + err.Error())
+			return
+		}
+		err = json.Unmarshal(b, &snap.Inflators)
+		if err != nil {
+			fmt.Println("Error: the file " + *inflatorsFile + " has problem with encoding:" + err.Error())
+			return
+		}
+	}
+	b, _ := json.MarshalIndent(snap, "", "  ")
+	err = ioutil.WriteFile(*file, b, 0644)
+	if err != nil {
+		fmt.Println("Error:", err.Error())
+		return
+	}
+	fmt.Println("The height", snap.Height, "has been exported to", *file)
+	fmt.Println("The app hash of the height:", hex.EncodeToString(snap.AppHash))
+	fmt.Println("The hash of the snapshot:", hex.EncodeToString(snap.Hash))
+}
+
+// importCommand verifies the snapshot against the trusted app hash and adds it to the app_state of the genesis,
+// so the new chain starts from the state of the snapshot
+func importCommand(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("file", "snapshot.json", "the file of the snapshot")
+	genesis := fs.String("genesis", "genesis.json", "the genesis file of tendermint, that the snapshot is added to")
+	inflatorsFile := fs.String("inflators-file", "", "the file to write the inflators of the snapshot")
+	appHash := fs.String("app-hash", "", "the trusted app hash of the height of the snapshot, from the header of the next block")
+	fs.Parse(args)
+
+	if len(*appHash) == 0 {
+		fmt.Println("Error: need the trusted app hash of the height to verify the snapshot.")
+		return
+	}
+	trusted, err := hex.DecodeString(*appHash)
+	if err != nil {
+		fmt.Println("Error: the app hash is not correct hex:", err.Error())
+		return
+	}
+
+	b, err := ioutil.ReadFile(*file)
+	if err != nil {
+		fmt.Println("Error: the file " + *file + " has problem:" + err.Error())
+		return
+	}
+	snap := dbpkg.Snapshot{}
+	err = json.Unmarshal(b, &snap)
+	if err != nil {
+		fmt.Println("Error: the file " + *file + " has problem with encoding:" + err.Error())
+		return
+	}
+	err = dbpkg.VerifySnapshot(&snap, trusted)
+	if err != nil {
+		fmt.Println("Error:", err.Error())
+		return
+	}
+
+	gb, err := ioutil.ReadFile(*genesis)
+	if err != nil {
+		fmt.Println("Error: the file " + *genesis + " has problem:" + err.Error())
+		return
+	}
+	// the genesis is kept as a map, so the fields of tendermint do not change
+	gen := map[string]interface{}{}
+	err = json.Unmarshal(gb, &gen)
+	if err != nil {
+		fmt.Println("Error: the file " + *genesis + " has problem with encoding:" + err.Error())
+		return
+	}
+	gen["app_state"] = ctrls.GenesisState{Snapshot: &snap}
+	gen["app_hash"] = hex.EncodeToString(snap.Hash)
+	gb, _ = json.MarshalIndent(gen, "", "  ")
+	err = ioutil.WriteFile(*genesis, gb, 0644)
+	if err != nil {
+		fmt.Println("Error:", err.Error())
+		return
+	}
+
+	if len(*inflatorsFile) > 0 {
+		ib, _ := json.Marshal(snap.Inflators)
+		err = ioutil.WriteFile(*inflatorsFile, ib, 0644)
+		if err != nil {
+			fmt.Println("Error:", err.Error())
+			return
+		}
+	}
+	fmt.Println("The snapshot of the height", snap.Height, "has been added to", *genesis)
+}